@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// CalVerConfig holds the settings of the calendar versioning scheme, see https://calver.org.
+type CalVerConfig struct {
+	Format string `yaml:"format" toml:"format"` // e.g. "YYYY.MM.MICRO" or "YY.0W.MICRO"
+}
+
+// calverTokens are the date tokens of a CalVer format, rendering the date of a release.
+var calverTokens = map[string]func(t time.Time) string{
+	"YYYY": func(t time.Time) string { return strconv.Itoa(t.Year()) },
+	"YY":   func(t time.Time) string { return strconv.Itoa(t.Year() - 2000) },
+	"0Y":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()-2000) },
+	"MM":   func(t time.Time) string { return strconv.Itoa(int(t.Month())) },
+	"0M":   func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) },
+	"WW":   func(t time.Time) string { _, w := t.ISOWeek(); return strconv.Itoa(w) },
+	"0W":   func(t time.Time) string { _, w := t.ISOWeek(); return fmt.Sprintf("%02d", w) },
+	"DD":   func(t time.Time) string { return strconv.Itoa(t.Day()) },
+	"0D":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+}
+
+// calverScheme versions releases by date. The format has three dot separated parts: two date
+// tokens naming the period and MICRO, which counts the releases of the period starting at 0.
+// Commits still decide whether a release is needed, but the kind of bump doesn't matter.
+type calverScheme struct {
+	tokens [2]string // Date tokens of the period
+	now    func() time.Time
+}
+
+// newCalVerScheme parses a CalVer format.
+func newCalVerScheme(format string) (*calverScheme, error) {
+	parts := strings.Split(format, ".")
+	if len(parts) != 3 || parts[2] != "MICRO" {
+		return nil, fmt.Errorf("invalid format %q, must be two date tokens followed by MICRO, e.g. YYYY.MM.MICRO", format)
+	}
+	for _, token := range parts[:2] {
+		if _, ok := calverTokens[token]; !ok {
+			return nil, fmt.Errorf("invalid format %q, unknown date token %q (valid: YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D)", format, token)
+		}
+	}
+	return &calverScheme{
+		tokens: [2]string{parts[0], parts[1]},
+		now:    func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+// parseVersion accepts zero padded parts, e.g. "2024.06.3".
+func (s *calverScheme) parseVersion(version string) (*semver.Version, error) {
+	core, pre, _ := strings.Cut(version, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid calendar version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar version %q", version)
+		}
+		parts[i] = strconv.FormatUint(n, 10)
+	}
+	normalized := strings.Join(parts, ".")
+	if pre != "" {
+		normalized += "-" + pre
+	}
+	return semver.StrictNewVersion(normalized)
+}
+
+func (s *calverScheme) formatVersion(v *semver.Version) string {
+	version := fmt.Sprintf("%s.%s.%d", s.pad(s.tokens[0], v.Major()), s.pad(s.tokens[1], v.Minor()), v.Patch())
+	if v.Prerelease() != "" {
+		version += "-" + v.Prerelease()
+	}
+	return version
+}
+
+// weekBased reports whether the period of the format is a week, see nextVersion.
+func (s *calverScheme) weekBased() bool {
+	for _, token := range s.tokens {
+		if token == "WW" || token == "0W" {
+			return true
+		}
+	}
+	return false
+}
+
+// pad writes a number of a period as the token does, with a leading zero for the 0 tokens.
+func (s *calverScheme) pad(token string, n uint64) string {
+	if strings.HasPrefix(token, "0") {
+		return fmt.Sprintf("%02d", n)
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+func (s *calverScheme) numberPattern() string {
+	return `[0-9]+`
+}
+
+// nextVersion returns the next release of the current period, e.g. 2024.6.3 after 2024.6.2.
+// The bump type is ignored, the MICRO counter starts over at 0 in a new period.
+func (s *calverScheme) nextVersion(current *semver.Version, bumpType string, preRelease bool, identifier string, tags []versionTag) (string, error) {
+	now := s.now()
+	if s.weekBased() {
+		// Weeks belong to the ISO year, e.g. 2027-01-01 is in week 53 of 2026. The Thursday of
+		// the week is always in its ISO year, so the year tokens render the year of the week.
+		now = now.AddDate(0, 0, 3-(int(now.Weekday())+6)%7)
+	}
+	period := [2]uint64{}
+	for i, token := range s.tokens {
+		n, err := strconv.ParseUint(calverTokens[token](now), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("error rendering calendar version token %s: %v", token, err)
+		}
+		period[i] = n
+	}
+
+	// Continue after the highest release of the period, pre-releases don't use up a MICRO
+	micro := uint64(0)
+	for _, tag := range tags {
+		v := tag.Version
+		if v.Major() == period[0] && v.Minor() == period[1] && v.Prerelease() == "" && v.Patch() >= micro {
+			micro = v.Patch() + 1
+		}
+	}
+	base := semver.New(period[0], period[1], micro, "", "")
+	if base.LessThan(current) || base.Equal(current) {
+		return "", fmt.Errorf("calendar version %s is not newer than the current version %s, check the format and the system clock", s.formatVersion(base), s.formatVersion(current))
+	}
+
+	if preRelease {
+		v, err := nextPrerelease(*base, identifier, tags)
+		if err != nil {
+			return "", err
+		}
+		base = &v
+	}
+	return s.formatVersion(base), nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestCalVerNextVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		now     string
+		current string
+		tags    []versionTag
+		want    string
+	}{
+		{name: "first release of the month", format: "YYYY.MM.MICRO", now: "2024-06-03", current: "2024.5.2", tags: testTags("2024.5.2"), want: "2024.6.0"},
+		{name: "next release of the month", format: "YYYY.0M.MICRO", now: "2024-06-03", current: "2024.6.1", tags: testTags("2024.6.1", "2024.6.0"), want: "2024.06.2"},
+		{name: "week", format: "YY.0W.MICRO", now: "2026-12-28", current: "26.52.0", tags: testTags("26.52.0"), want: "26.53.0"},
+		{name: "week 53 in the next calendar year", format: "YY.0W.MICRO", now: "2027-01-01", current: "26.53.0", tags: testTags("26.53.0"), want: "26.53.1"},
+		{name: "first week of the ISO year", format: "YY.0W.MICRO", now: "2027-01-04", current: "26.53.1", tags: testTags("26.53.1"), want: "27.01.0"},
+		{name: "week 1 in the previous calendar year", format: "YYYY.WW.MICRO", now: "2024-12-30", current: "2024.52.0", tags: testTags("2024.52.0"), want: "2025.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newCalVerScheme(tt.format)
+			if err != nil {
+				t.Fatalf("newCalVerScheme() error = %v", err)
+			}
+			now, _ := time.Parse("2006-01-02", tt.now)
+			s.now = func() time.Time { return now }
+			got, err := s.nextVersion(semver.MustParse(tt.current), "patch", false, "", tt.tags)
+			if err != nil {
+				t.Fatalf("nextVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("nextVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// changelogEntryTemplate renders a single commit of the release notes. It is available to
+// user templates as {{template "entry" .}}.
+const changelogEntryTemplate = `{{define "entry"}}{{if not .Conventional}}{{linkReferences .Header}}{{template "entryLinks" .}}{{else if .Breaking}}- **BREAKING CHANGE:** {{linkReferences .Subject}}{{template "entryLinks" .}}{{with .BreakingDescription}}
+{{indent 2 .}}{{end}}{{else}}- **{{.Type}}:** {{linkReferences .Subject}}{{template "entryLinks" .}}{{end}}{{end}}`
+
+// changelogEntryLinksTemplate renders the commit link, the issue references of the footers and
+// the author after an entry. It is available to user templates as {{template "entryLinks" .}}.
+const changelogEntryLinksTemplate = `{{define "entryLinks"}}{{with commitLink .}} ({{.}}){{end}}{{references .}}{{with author .}} by {{.}}{{end}}{{end}}`
+
+// changelogCommitsTemplate renders the commits of a section, in blocks per scope if
+// changelog.group_by_scope is set. It is available to user templates as {{template "commits" .}}.
+const changelogCommitsTemplate = `{{define "commits"}}{{if .Scopes}}{{range .Scopes}}{{if .Name}}- **{{.Name}}:**
+{{range .Commits}}  - {{if .Breaking}}**BREAKING CHANGE:** {{else}}**{{.Type}}:** {{end}}{{linkReferences .Subject}}{{template "entryLinks" .}}{{with .BreakingDescription}}
+{{indent 4 .}}{{end}}
+{{end}}{{else}}{{range .Commits}}{{template "entry" .}}
+{{end}}{{end}}{{end}}{{else}}{{range .Commits}}{{template "entry" .}}
+{{end}}{{end}}{{end}}`
+
+// defaultChangelogTemplate renders the release notes of a version as Markdown.
+const defaultChangelogTemplate = `## {{.Tag}} ({{.Date}})
+
+{{range .Groups}}### {{.Title}}
+
+{{template "commits" .}}
+{{end}}`
+
+// changelogTemplateFuncs are the functions available in changelog templates.
+var changelogTemplateFuncs = template.FuncMap{
+	"shortSHA": shortSHA,
+	"join":     strings.Join,
+	"indent":   indent,
+}
+
+// indent indents every line of text by n spaces, e.g. to keep a multi-line breaking change
+// description inside its list item. Empty lines stay empty.
+func indent(n int, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = strings.Repeat(" ", n) + line
+		} else {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ChangelogSectionConfig defines a section of the release notes and the commits listed in it.
+// A commit is listed in the first section taking it as a breaking change, else by one of its
+// footers, else by its type, else by the "*" wildcard. Hidden sections leave their commits out.
+type ChangelogSectionConfig struct {
+	Key      string   `yaml:"key,omitempty" toml:"key,omitempty"` // Identifier for templates, derived from the title if empty
+	Title    string   `yaml:"title,omitempty" toml:"title,omitempty"`
+	Types    []string `yaml:"types,omitempty" toml:"types,omitempty"`       // Commit types, "*" for all commits including non-conventional ones
+	Footers  []string `yaml:"footers,omitempty" toml:"footers,omitempty"`   // Footer tokens, e.g. "Security"
+	Breaking bool     `yaml:"breaking,omitempty" toml:"breaking,omitempty"` // Breaking changes of any type
+	Hidden   bool     `yaml:"hidden,omitempty" toml:"hidden,omitempty"`
+}
+
+// key returns the identifier of the section.
+func (s ChangelogSectionConfig) key() string {
+	if s.Key != "" {
+		return s.Key
+	}
+	return strings.ToLower(strings.Join(strings.Fields(s.Title), "-"))
+}
+
+// changelogFormat defines how commits are grouped and rendered in a changelog.
+type changelogFormat struct {
+	sections []ChangelogSectionConfig // Default sections in display order
+	template string                   // Default template
+}
+
+// changelogFormats are the supported changelog formats by name.
+var changelogFormats = map[string]changelogFormat{
+	"default": {
+		sections: []ChangelogSectionConfig{
+			{Key: "breaking", Title: "BREAKING CHANGES", Breaking: true}, // Breaking changes are not repeated in the other sections
+			{Key: "features", Title: "Features", Types: []string{"feat"}},
+			{Key: "fixes", Title: "Bug Fixes", Types: []string{"fix"}},
+			// Other types that might be relevant for a changelog, and non-conventional commits as is
+			{Key: "other", Title: "Other Changes", Types: []string{"*"}},
+			{Key: "hidden", Types: []string{"docs", "style", "test", "chore"}, Hidden: true},
+		},
+		template: defaultChangelogTemplate,
+	},
+	"keepachangelog": {
+		sections: keepAChangelogSections,
+		template: keepAChangelogTemplate,
+	},
+}
+
+// changelogSections returns the configured sections of the release notes, or those of the format.
+func (c *Config) changelogSections() []ChangelogSectionConfig {
+	if len(c.Changelog.Sections) > 0 {
+		return c.Changelog.Sections
+	}
+	return changelogFormats[c.Changelog.Format].sections
+}
+
+// classifyCommit returns the index of the section listing the commit, or -1 to leave it out.
+func classifyCommit(sections []ChangelogSectionConfig, commit Commit) int {
+	rules := []func(ChangelogSectionConfig) bool{
+		func(s ChangelogSectionConfig) bool { return s.Breaking && commit.Breaking },
+		func(s ChangelogSectionConfig) bool {
+			for _, token := range s.Footers {
+				if _, ok := commit.FooterValue(token); ok {
+					return true
+				}
+			}
+			return false
+		},
+		func(s ChangelogSectionConfig) bool {
+			return commit.Conventional && containsString(s.Types, commit.Type)
+		},
+		func(s ChangelogSectionConfig) bool { return containsString(s.Types, "*") },
+	}
+	for _, matches := range rules {
+		for i, section := range sections {
+			if !matches(section) {
+				continue
+			}
+			if section.Hidden {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// validateChangelogSections checks the configured sections of the release notes.
+func (c *Config) validateChangelogSections() []configError {
+	var errs []configError
+	for i, section := range c.Changelog.Sections {
+		key := fmt.Sprintf("changelog.sections.%d", i)
+		if strings.TrimSpace(section.Title) == "" && !section.Hidden {
+			errs = append(errs, configError{Key: key + ".title", Message: "must not be empty unless the section is hidden"})
+		}
+		if len(section.Types) == 0 && len(section.Footers) == 0 && !section.Breaking {
+			errs = append(errs, configError{Key: key, Message: "a section needs types, footers or breaking"})
+		}
+		for j, t := range section.Types {
+			if t != "*" && !containsString(c.Types, t) {
+				errs = append(errs, configError{Key: fmt.Sprintf("%s.types.%d", key, j), Message: fmt.Sprintf("commit type %q is not listed in types", t)})
+			}
+		}
+	}
+	return errs
+}
+
+// changelogData is the data the changelog template is rendered with.
+type changelogData struct {
+	Tag             string // Tag of the new version, e.g. "v1.2.0"
+	Version         string // e.g. "1.2.0"
+	PreviousTag     string // Tag of the previous version, empty for the first release
+	PreviousVersion string
+	Date            string // Release date, 2006-01-02
+	RepositoryURL   string // Web URL of the repository, empty if unknown
+	CompareURL      string // Diff between the previous and the new tag, empty if unknown
+	Groups          []changelogGroup
+	Commits         []Commit // All commits in the release notes, newest first
+	Contributors    []string // Authors of the commits, sorted
+}
+
+// changelogGroup is a section of the release notes.
+type changelogGroup struct {
+	Key     string // e.g. features, see changelogFormats
+	Title   string
+	Notes   []string // Lines written by hand, e.g. in the [Unreleased] section of a Keep a Changelog file
+	Commits []Commit
+	Scopes  []changelogScope // Commits by scope if changelog.group_by_scope is set
+}
+
+// changelogScope lists the commits of a section that share a scope.
+type changelogScope struct {
+	Name    string // Empty for commits without a scope
+	Commits []Commit
+}
+
+// scopeCommits groups commits by their scope, sorted by name with the unscoped commits last.
+func scopeCommits(commits []Commit) []changelogScope {
+	var scopes []changelogScope
+	for _, commit := range commits {
+		i := 0
+		for i < len(scopes) && scopes[i].Name != commit.Scope {
+			i++
+		}
+		if i == len(scopes) {
+			scopes = append(scopes, changelogScope{Name: commit.Scope})
+		}
+		scopes[i].Commits = append(scopes[i].Commits, commit)
+	}
+	sort.SliceStable(scopes, func(i, j int) bool {
+		if scopes[i].Name == "" || scopes[j].Name == "" {
+			return scopes[j].Name == "" && scopes[i].Name != ""
+		}
+		return scopes[i].Name < scopes[j].Name
+	})
+	return scopes
+}
+
+// renderReleaseNotes renders the release notes of the commits with the changelog template.
+// The version fields of data are set by the caller, the rest is filled in here. Groups already
+// in data carry hand written notes, which are merged into the groups of the same title.
+func renderReleaseNotes(cfg *Config, commits []Commit, data changelogData) (string, error) {
+	format := changelogFormats[cfg.Changelog.Format]
+	tmpl, err := parseChangelogTemplate(cfg.Changelog.Template, format.template)
+	if err != nil {
+		return "", err
+	}
+
+	sections := cfg.changelogSections()
+	groups := make([]changelogGroup, len(sections))
+	for i, section := range sections {
+		groups[i] = changelogGroup{Key: section.key(), Title: section.Title}
+	}
+	for _, noted := range data.Groups {
+		merged := false
+		for i := range groups {
+			if strings.EqualFold(groups[i].Title, noted.Title) {
+				groups[i].Notes = append(groups[i].Notes, noted.Notes...)
+				merged = true
+			}
+		}
+		if !merged {
+			groups = append(groups, noted)
+		}
+	}
+	data.Groups = nil
+
+	authors := map[string]bool{}
+	for _, commit := range commits {
+		if shouldSkipCI(commit.Message, cfg.SkipCIPatterns) || commit.Merge {
+			continue // Skip commits that should not be in release notes
+		}
+
+		i := classifyCommit(sections, commit)
+		if i < 0 {
+			continue
+		}
+		groups[i].Commits = append(groups[i].Commits, commit)
+		data.Commits = append(data.Commits, commit)
+		if !authors[commit.Author] {
+			authors[commit.Author] = true
+			data.Contributors = append(data.Contributors, commit.Author)
+		}
+	}
+	sort.Strings(data.Contributors)
+
+	for _, group := range groups {
+		if cfg.Changelog.GroupByScope {
+			group.Scopes = scopeCommits(group.Commits)
+		}
+		if len(group.Commits) > 0 || len(group.Notes) > 0 {
+			data.Groups = append(data.Groups, group)
+		}
+	}
+	if data.Date == "" {
+		data.Date = time.Now().Format("2006-01-02")
+	}
+	data.RepositoryURL = repositoryURL(cfg.Remote)
+	data.CompareURL = compareURL(data.RepositoryURL, data.PreviousTag, data.Tag)
+	tmpl.Funcs(newChangelogLinker(cfg, data.RepositoryURL).funcs())
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error rendering changelog template: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// parseChangelogTemplate parses the changelog template file, or the default template if path is empty.
+func parseChangelogTemplate(path, defaultTemplate string) (*template.Template, error) {
+	text := defaultTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading changelog template: %v", err)
+		}
+		text = string(data)
+	}
+
+	tmpl := template.New("changelog").Funcs(tagTemplateFuncs).Funcs(changelogTemplateFuncs).Funcs((&changelogLinker{}).funcs())
+	for _, entry := range []string{changelogEntryLinksTemplate, changelogEntryTemplate, changelogCommitsTemplate, keepAChangelogEntryTemplate, keepAChangelogCommitsTemplate} {
+		if _, err := tmpl.Parse(entry); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("error parsing changelog template %s: %v", path, err)
+	}
+	return tmpl, nil
+}
+
+// generateReleaseNotes renders the release notes for the given commits and adds them to the changelog file.
+// The title and introduction of the file stay at the top, and a section of the same version written
+// by an earlier run is replaced, so a release can be retried without duplicating its notes.
+// It reports whether the file was changed.
+func generateReleaseNotes(cfg *Config, commits []Commit, data changelogData, outputPath string) (bool, error) {
+	// Read existing changelog content if file exists
+	var existingContent []byte
+	if _, err := os.Stat(outputPath); err == nil { // File exists
+		existingContent, err = os.ReadFile(outputPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to read existing changelog file '%s': %v", outputPath, err)
+		}
+	}
+
+	doc, err := parseChangelog(string(existingContent))
+	if err != nil {
+		return false, withCode(errCodeChangelogFormat, fmt.Errorf("refusing to update changelog file '%s', it is not in a recognized format: %v", outputPath, err))
+	}
+
+	if cfg.Changelog.Format == "keepachangelog" {
+		if err := updateKeepAChangelog(cfg, &doc, commits, data); err != nil {
+			return false, err
+		}
+	} else {
+		section, err := renderReleaseNotes(cfg, commits, data)
+		if err != nil {
+			return false, err
+		}
+		// Sections are named after the tag, the version is accepted as well for hand written headings
+		doc.putSection(changelogSection{Name: data.Tag, Text: section}, data.Version)
+	}
+	finalContent := []byte(doc.String())
+	if existingContent != nil && bytes.Equal(finalContent, existingContent) {
+		return false, nil // e.g. a retried release whose notes are already in the file
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, finalContent, 0644); err != nil {
+		return false, fmt.Errorf("failed to write changelog to file '%s': %v", outputPath, err)
+	}
+
+	return true, nil
+}
+
+// changelogDocument is a Markdown changelog split into its parts.
+type changelogDocument struct {
+	Preamble string             // Title and introduction before the first version section
+	Sections []changelogSection // "## " sections, newest first
+	Links    []string           // Link reference definitions at the end, e.g. "[1.0.0]: https://..."
+}
+
+// changelogSection is a "## " section of a changelog, usually the release notes of a version.
+type changelogSection struct {
+	Name string // Version or tag of the heading without brackets, e.g. "1.2.0" or "Unreleased"
+	Text string // The whole section including the heading line
+}
+
+// changelogLinkPattern matches a Markdown link reference definition.
+var changelogLinkPattern = regexp.MustCompile(`^\[([^\]]+)\]:\s*\S+`)
+
+// changelogHeadingPattern matches the heading of a version section and captures its name,
+// e.g. "## [1.2.0] - 2024-06-01" or "## v1.2.0 (2024-06-01)".
+var changelogHeadingPattern = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+
+// changelogVersionPattern matches the name of a version section, e.g. "1.2.0", "v1.2.0" or "api/v1.2.0".
+var changelogVersionPattern = regexp.MustCompile(`[0-9]+\.[0-9]+`)
+
+// parseChangelog splits a changelog into its parts. An error is returned if the file does not
+// look like a changelog made of version sections, as it could not be updated without
+// mangling it: release notes outside a version section, a title below the version sections,
+// headings without a version, or the same version twice.
+func parseChangelog(content string) (changelogDocument, error) {
+	var doc changelogDocument
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	// Link reference definitions at the end of the file
+	end := len(lines)
+	for end > 0 && (strings.TrimSpace(lines[end-1]) == "" || changelogLinkPattern.MatchString(lines[end-1])) {
+		end--
+	}
+	for _, line := range lines[end:] {
+		if strings.TrimSpace(line) != "" {
+			doc.Links = append(doc.Links, line)
+		}
+	}
+
+	var preamble []string
+	fenced := false // Inside a fenced code block, where lines are not Markdown
+	for i, line := range lines[:end] {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+
+		switch {
+		case fenced:
+		case strings.HasPrefix(line, "## "):
+			name := ""
+			if m := changelogHeadingPattern.FindStringSubmatch(line); m != nil {
+				name = m[1]
+			}
+			if !strings.EqualFold(name, "Unreleased") && !changelogVersionPattern.MatchString(name) {
+				return doc, fmt.Errorf("line %d: heading %q does not name a version", i+1, line)
+			}
+			if doc.section(name) >= 0 {
+				return doc, fmt.Errorf("line %d: duplicate section for %s", i+1, name)
+			}
+			doc.Sections = append(doc.Sections, changelogSection{Name: name})
+		case strings.HasPrefix(line, "# ") && len(doc.Sections) > 0:
+			return doc, fmt.Errorf("line %d: title %q below the version sections, it belongs at the top of the file", i+1, line)
+		case len(doc.Sections) == 0 && (strings.HasPrefix(line, "### ") || strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")):
+			return doc, fmt.Errorf("line %d: %q is not inside a version section", i+1, line)
+		}
+
+		if len(doc.Sections) == 0 {
+			preamble = append(preamble, line)
+			continue
+		}
+		section := &doc.Sections[len(doc.Sections)-1]
+		section.Text += line + "\n"
+	}
+	if fenced {
+		return doc, fmt.Errorf("unterminated code block")
+	}
+	doc.Preamble = strings.Join(preamble, "\n")
+	return doc, nil
+}
+
+// String renders the document, separating its parts by single blank lines.
+func (d changelogDocument) String() string {
+	var parts []string
+	if preamble := strings.TrimSpace(d.Preamble); preamble != "" {
+		parts = append(parts, preamble)
+	}
+	for _, section := range d.Sections {
+		parts = append(parts, strings.TrimRight(section.Text, "\n"))
+	}
+	if len(d.Links) > 0 {
+		parts = append(parts, strings.Join(d.Links, "\n"))
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// section returns the index of the section with the given name (case insensitive), or -1.
+func (d changelogDocument) section(name string) int {
+	for i, section := range d.Sections {
+		if strings.EqualFold(section.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// putSection replaces the section with the name of the given section, or one of the aliases,
+// and otherwise adds it as the newest version, below the [Unreleased] section if there is one.
+func (d *changelogDocument) putSection(section changelogSection, aliases ...string) {
+	for _, name := range append([]string{section.Name}, aliases...) {
+		if i := d.section(name); i >= 0 {
+			d.Sections[i] = section
+			return
+		}
+	}
+	insert := d.section("Unreleased") + 1
+	d.Sections = append(d.Sections[:insert], append([]changelogSection{section}, d.Sections[insert:]...)...)
+}
+
+// setLink replaces the link reference definition of a label. New links are added at the top,
+// below the [unreleased] link, as the sections are ordered newest first.
+func (d *changelogDocument) setLink(label, url string) {
+	link := fmt.Sprintf("[%s]: %s", label, url)
+	insert := 0
+	for i, existing := range d.Links {
+		m := changelogLinkPattern.FindStringSubmatch(existing)
+		if m == nil {
+			continue
+		}
+		if strings.EqualFold(m[1], label) {
+			d.Links[i] = link
+			return
+		}
+		if strings.EqualFold(m[1], "unreleased") {
+			insert = i + 1
+		}
+	}
+	d.Links = append(d.Links[:insert], append([]string{link}, d.Links[insert:]...)...)
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndent(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "one line", want: "  one line"},
+		{text: "first\nsecond", want: "  first\n  second"},
+		{text: "first\n\n## heading", want: "  first\n\n  ## heading"},
+	}
+	for _, tt := range tests {
+		if got := indent(2, tt.text); got != tt.want {
+			t.Errorf("indent(2, %q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMultiLineBreakingDescription(t *testing.T) {
+	commit := parseCommit("feat(api)!: drop the v1 API\n\nBREAKING CHANGE: the v1 endpoints are removed.\n## Migration\nCall the v2 endpoints instead.")
+
+	tests := []struct {
+		name         string
+		format       string
+		groupByScope bool
+		indent       string
+	}{
+		{name: "default", format: "default", indent: "  "},
+		{name: "default by scope", format: "default", groupByScope: true, indent: "    "},
+		{name: "keep a changelog", format: "keepachangelog", indent: "  "},
+		{name: "keep a changelog by scope", format: "keepachangelog", groupByScope: true, indent: "    "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Changelog.Format = tt.format
+			cfg.Changelog.GroupByScope = tt.groupByScope
+			notes, err := renderReleaseNotes(cfg, []Commit{commit}, changelogData{Tag: "v2.0.0", Version: "2.0.0", Date: "2024-06-01"})
+			if err != nil {
+				t.Fatalf("renderReleaseNotes() error = %v", err)
+			}
+			for _, line := range strings.Split(commit.BreakingDescription(), "\n") {
+				if !strings.Contains(notes, "\n"+tt.indent+line+"\n") {
+					t.Errorf("description line %q is not indented by %d spaces in:\n%s", line, len(tt.indent), notes)
+				}
+			}
+			if _, err := parseChangelog(notes); err != nil {
+				t.Errorf("parseChangelog() of the rendered notes error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseChangelogRefusesUnknownLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "list item before the sections", content: "# Changelog\n\n- fixed a bug\n\n## 1.0.0\n\n- initial release\n"},
+		{name: "subheading before the sections", content: "# Changelog\n\n### Bug Fixes\n\n## 1.0.0\n"},
+		{name: "heading without a version", content: "# Changelog\n\n## Notes\n\nSome notes.\n"},
+		{name: "duplicate section", content: "## [1.0.0]\n\n- a\n\n## 1.0.0\n\n- b\n"},
+		{name: "duplicate unreleased section", content: "## [Unreleased]\n\n## unreleased\n"},
+		{name: "title below the sections", content: "## 1.0.0\n\n- a\n\n# Changelog\n"},
+		{name: "unterminated code block", content: "## 1.0.0\n\n```\n## 0.9.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseChangelog(tt.content); err == nil {
+				t.Errorf("parseChangelog() accepted:\n%s", tt.content)
+			}
+		})
+	}
+}
+
+func TestParseChangelogRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		sections []string
+	}{
+		{name: "empty", content: "", sections: nil},
+		{name: "title only", content: "# Changelog\n\nAll notable changes.\n"},
+		{
+			name:     "keep a changelog",
+			content:  "# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2024-06-01\n\n### Added\n\n- b\n\n## [1.0.0] - 2024-05-01\n\n- a\n\n[unreleased]: https://example.com/compare/v1.1.0...HEAD\n[1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n",
+			sections: []string{"Unreleased", "1.1.0", "1.0.0"},
+		},
+		{
+			name:     "headings in code blocks",
+			content:  "## v1.0.0 (2024-06-01)\n\n```\n## not a section\n# not a title\n```\n",
+			sections: []string{"v1.0.0"},
+		},
+		{
+			name:     "package tags",
+			content:  "## api/v1.1.0\n\n- b\n\n## api/v1.0.0\n\n- a\n",
+			sections: []string{"api/v1.1.0", "api/v1.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseChangelog(tt.content)
+			if err != nil {
+				t.Fatalf("parseChangelog() error = %v", err)
+			}
+			var names []string
+			for _, section := range doc.Sections {
+				names = append(names, section.Name)
+			}
+			if !reflect.DeepEqual(names, tt.sections) {
+				t.Errorf("sections = %q, want %q", names, tt.sections)
+			}
+			if tt.content != "" && doc.String() != tt.content {
+				t.Errorf("String() =\n%s\nwant\n%s", doc.String(), tt.content)
+			}
+		})
+	}
+}
+
+func TestPutSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		section  changelogSection
+		aliases  []string
+		sections []string
+		text     string // Text of the section with the new name
+	}{
+		{
+			name:     "adds the newest version at the top",
+			content:  "# Changelog\n\n## v1.0.0\n\n- a\n",
+			section:  changelogSection{Name: "v1.1.0", Text: "## v1.1.0\n\n- b\n"},
+			sections: []string{"v1.1.0", "v1.0.0"},
+		},
+		{
+			name:     "adds below the unreleased section",
+			content:  "## [Unreleased]\n\n## [1.0.0]\n\n- a\n",
+			section:  changelogSection{Name: "1.1.0", Text: "## [1.1.0]\n\n- b\n"},
+			sections: []string{"Unreleased", "1.1.0", "1.0.0"},
+		},
+		{
+			name:     "replaces the section of the same version",
+			content:  "## v1.1.0\n\n- old\n\n## v1.0.0\n\n- a\n",
+			section:  changelogSection{Name: "v1.1.0", Text: "## v1.1.0\n\n- new\n"},
+			sections: []string{"v1.1.0", "v1.0.0"},
+		},
+		{
+			name:     "replaces a hand written section named after the version",
+			content:  "## [1.1.0]\n\n- old\n\n## [1.0.0]\n\n- a\n",
+			section:  changelogSection{Name: "v1.1.0", Text: "## v1.1.0\n\n- new\n"},
+			aliases:  []string{"1.1.0"},
+			sections: []string{"v1.1.0", "1.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseChangelog(tt.content)
+			if err != nil {
+				t.Fatalf("parseChangelog() error = %v", err)
+			}
+			doc.putSection(tt.section, tt.aliases...)
+			var names []string
+			for _, section := range doc.Sections {
+				names = append(names, section.Name)
+			}
+			if !reflect.DeepEqual(names, tt.sections) {
+				t.Errorf("sections = %q, want %q", names, tt.sections)
+			}
+			if got := doc.Sections[doc.section(tt.section.Name)].Text; got != tt.section.Text {
+				t.Errorf("section text = %q, want %q", got, tt.section.Text)
+			}
+		})
+	}
+}
+
+func TestGenerateReleaseNotesRetry(t *testing.T) {
+	for _, format := range []string{"default", "keepachangelog"} {
+		t.Run(format, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Changelog.Format = format
+			path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+			existing := "# Changelog\n\nAll notable changes.\n\n## [1.0.0] - 2024-05-01\n\n- initial release\n"
+			if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+			data := changelogData{Tag: "v1.1.0", Version: "1.1.0", PreviousTag: "v1.0.0", PreviousVersion: "1.0.0", Date: "2024-06-01"}
+
+			first := []Commit{parseCommit("feat: add login")}
+			if changed, err := generateReleaseNotes(cfg, first, data, path); err != nil || !changed {
+				t.Fatalf("generateReleaseNotes() = %v, %v, want true, nil", changed, err)
+			}
+			written, _ := os.ReadFile(path)
+
+			// A retry with the same commits leaves the file alone
+			if changed, err := generateReleaseNotes(cfg, first, data, path); err != nil || changed {
+				t.Fatalf("retried generateReleaseNotes() = %v, %v, want false, nil", changed, err)
+			}
+			if retried, _ := os.ReadFile(path); string(retried) != string(written) {
+				t.Errorf("retry changed the changelog:\n%s\nwant\n%s", retried, written)
+			}
+
+			// A retry with more commits replaces the notes of the version
+			second := []Commit{parseCommit("fix: handle empty passwords"), parseCommit("feat: add login")}
+			if changed, err := generateReleaseNotes(cfg, second, data, path); err != nil || !changed {
+				t.Fatalf("retried generateReleaseNotes() = %v, %v, want true, nil", changed, err)
+			}
+			content, _ := os.ReadFile(path)
+			doc, err := parseChangelog(string(content))
+			if err != nil {
+				t.Fatalf("parseChangelog() error = %v", err)
+			}
+			if (doc.section("1.1.0") < 0 && doc.section("v1.1.0") < 0) || doc.section("1.0.0") < 0 {
+				t.Errorf("missing version sections:\n%s", content)
+			}
+			if n := strings.Count(string(content), "add login"); n != 1 {
+				t.Errorf("the notes appear %d times:\n%s", n, content)
+			}
+			if !strings.Contains(string(content), "handle empty passwords") || !strings.HasPrefix(string(content), "# Changelog\n\nAll notable changes.\n\n## ") {
+				t.Errorf("unexpected changelog:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestGenerateReleaseNotesRefusesUnknownLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	existing := "# Changelog\n\n- fixed a bug\n"
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := changelogData{Tag: "v1.1.0", Version: "1.1.0", Date: "2024-06-01"}
+	_, err := generateReleaseNotes(defaultConfig(), []Commit{parseCommit("feat: add login")}, data, path)
+	if code := errorCode(err); code != errCodeChangelogFormat {
+		t.Errorf("generateReleaseNotes() error = %v with code %q, want code %q", err, code, errCodeChangelogFormat)
+	}
+	if content, _ := os.ReadFile(path); string(content) != existing {
+		t.Errorf("the changelog was changed:\n%s", content)
+	}
+}
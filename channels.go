@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// ChannelConfig maps branches to a pre-release channel.
+// Channels are evaluated in order and the first one whose branch pattern matches is used.
+type ChannelConfig struct {
+	Branch     string `yaml:"branch" toml:"branch"`                             // Glob matched against the branch name, e.g. "release/*"
+	Prerelease string `yaml:"prerelease,omitempty" toml:"prerelease,omitempty"` // Pre-release identifier, e.g. "rc"; empty uses the branch name
+	Skip       bool   `yaml:"skip,omitempty" toml:"skip,omitempty"`             // Don't release from matching branches at all
+}
+
+// prereleaseIdentifierPattern matches a valid dot separated semver pre-release identifier without the counter.
+var prereleaseIdentifierPattern = regexp.MustCompile(`^[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*$`)
+
+// channelFor returns the channel the branch belongs to.
+func (c *Config) channelFor(branch string) (ChannelConfig, bool) {
+	for _, channel := range c.Channels {
+		if ok, _ := path.Match(channel.Branch, branch); ok {
+			return channel, true
+		}
+	}
+	return ChannelConfig{}, false
+}
+
+// identifier returns the pre-release identifier of the channel for the branch.
+func (ch ChannelConfig) identifier(branch string) string {
+	if ch.Prerelease != "" {
+		return ch.Prerelease
+	}
+	return sanitizeBranchName(branch)
+}
+
+// sanitizeBranchName turns a branch name into a valid pre-release identifier.
+func sanitizeBranchName(branch string) string {
+	return regexp.MustCompile(`[^a-zA-Z0-9-]`).ReplaceAllString(branch, "-")
+}
+
+// validateChannels checks the pre-release channel definitions.
+func (c *Config) validateChannels() []configError {
+	var errs []configError
+	for i, channel := range c.Channels {
+		key := fmt.Sprintf("channels.%d", i)
+		if channel.Branch == "" {
+			errs = append(errs, configError{Key: key, Message: "a channel needs a branch pattern"})
+		} else if _, err := path.Match(channel.Branch, ""); err != nil {
+			errs = append(errs, configError{Key: key + ".branch", Message: fmt.Sprintf("invalid branch pattern %q", channel.Branch)})
+		}
+		if channel.Prerelease != "" && !prereleaseIdentifierPattern.MatchString(channel.Prerelease) {
+			errs = append(errs, configError{Key: key + ".prerelease", Message: fmt.Sprintf("invalid pre-release identifier %q", channel.Prerelease)})
+		}
+		if channel.Skip && channel.Prerelease != "" {
+			errs = append(errs, configError{Key: key + ".skip", Message: "a skipped channel cannot have a pre-release identifier"})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ciProviders are the supported values of ci_provider besides auto and none, in detection order.
+var ciProviders = []string{"github", "gitlab", "jenkins", "buildkite", "azure", "generic"}
+
+// ciEnvironment is the build of a CI system SemVerGo runs in, read from its environment variables.
+type ciEnvironment struct {
+	Provider    string // One of ciProviders, empty if not running in CI
+	Branch      string // Branch being built, the source branch of a pull request
+	Tag         string // Tag being built, if the build was triggered by a tag
+	PullRequest string // Number of the pull request being built, if any
+	MergeRef    bool   // HEAD is the merge of the pull request into its target, not a commit of the branch
+}
+
+// pullRefPattern matches the ref of a pull request build, e.g. "refs/pull/123/merge".
+var pullRefPattern = regexp.MustCompile(`^refs/pull/([0-9]+)/(merge|head)$`)
+
+// detectCI returns the CI environment of the configured provider, or of the provider detected
+// from the environment variables if ci_provider is auto. It is empty if ci_provider is none.
+func detectCI(cfg *Config) ciEnvironment {
+	provider := cfg.CIProvider
+	if provider == "none" {
+		return ciEnvironment{}
+	}
+	if provider == "auto" {
+		provider = ""
+		for _, name := range ciProviders {
+			if ciDetected(name) {
+				provider = name
+				break
+			}
+		}
+	}
+
+	env := ciEnvironment{Provider: provider}
+	switch provider {
+	case "github":
+		if os.Getenv("GITHUB_REF_TYPE") == "tag" {
+			env.Tag = os.Getenv("GITHUB_REF_NAME")
+		} else if m := pullRefPattern.FindStringSubmatch(os.Getenv("GITHUB_REF")); m != nil {
+			env.Branch, env.PullRequest = os.Getenv("GITHUB_HEAD_REF"), m[1]
+			env.MergeRef = m[2] == "merge"
+		} else {
+			env.Branch = os.Getenv("GITHUB_REF_NAME")
+		}
+	case "gitlab":
+		env.Tag = os.Getenv("CI_COMMIT_TAG")
+		env.Branch = os.Getenv("CI_COMMIT_BRANCH")
+		if source := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"); source != "" {
+			env.Branch, env.PullRequest = source, os.Getenv("CI_MERGE_REQUEST_IID")
+			env.MergeRef = os.Getenv("CI_MERGE_REQUEST_EVENT_TYPE") == "merged_result"
+		}
+	case "jenkins":
+		env.Tag = os.Getenv("TAG_NAME")
+		env.PullRequest = os.Getenv("CHANGE_ID")
+		switch {
+		case os.Getenv("CHANGE_BRANCH") != "":
+			env.Branch = os.Getenv("CHANGE_BRANCH")
+		case os.Getenv("BRANCH_NAME") != "" && env.PullRequest == "":
+			env.Branch = os.Getenv("BRANCH_NAME")
+		default: // The git plugin reports the remote tracking branch, e.g. "origin/main"
+			env.Branch = strings.TrimPrefix(os.Getenv("GIT_BRANCH"), cfg.Remote+"/")
+		}
+	case "buildkite":
+		env.Tag = os.Getenv("BUILDKITE_TAG")
+		env.Branch = os.Getenv("BUILDKITE_BRANCH")
+		if pr := os.Getenv("BUILDKITE_PULL_REQUEST"); pr != "" && pr != "false" {
+			env.PullRequest = pr
+		}
+	case "azure":
+		ref := os.Getenv("BUILD_SOURCEBRANCH")
+		switch {
+		case strings.HasPrefix(ref, "refs/tags/"):
+			env.Tag = strings.TrimPrefix(ref, "refs/tags/")
+		case strings.HasPrefix(ref, "refs/heads/"):
+			env.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		}
+		if source := os.Getenv("SYSTEM_PULLREQUEST_SOURCEBRANCH"); source != "" {
+			env.MergeRef = pullRefPattern.MatchString(ref) && strings.HasSuffix(ref, "/merge")
+			env.Branch = strings.TrimPrefix(source, "refs/heads/")
+			env.PullRequest = os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER")
+			if env.PullRequest == "" {
+				env.PullRequest = os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID")
+			}
+		}
+	}
+	return env
+}
+
+// ciDetected reports whether the environment variables of the CI provider are set.
+func ciDetected(provider string) bool {
+	switch provider {
+	case "github":
+		return os.Getenv("GITHUB_ACTIONS") == "true"
+	case "gitlab":
+		return os.Getenv("GITLAB_CI") != ""
+	case "jenkins":
+		return os.Getenv("JENKINS_URL") != ""
+	case "buildkite":
+		return os.Getenv("BUILDKITE") == "true"
+	case "azure":
+		return os.Getenv("TF_BUILD") != ""
+	case "generic":
+		return os.Getenv("CI") != "" && os.Getenv("CI") != "false"
+	}
+	return false
+}
+
+// currentBranch returns the branch being released: the branch reported by the CI provider,
+// or the checked out branch.
+func currentBranch(cfg *Config) (string, error) {
+	if cfg.CIEnv.Branch != "" {
+		return cfg.CIEnv.Branch, nil
+	}
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached and the branch is not known from the CI environment, set it with -branch")
+	}
+	return branch, nil
+}
+
+// ciOutputs returns the results of the run as output variables, named in lower snake case.
+// The variables of a monorepo package are prefixed with its name, e.g. "api_version".
+func ciOutputs(r *runReport) map[string]string {
+	outputs := map[string]string{"success": fmt.Sprint(r.Success)}
+	if r.Error != nil {
+		outputs["error_code"] = r.Error.Code
+	}
+	var released []string
+	for _, release := range r.Releases {
+		prefix := ""
+		if release.Package != "" {
+			prefix = strings.ToLower(ciVariableChars.ReplaceAllString(release.Package, "_")) + "_"
+		}
+		created := false
+		for _, action := range release.Actions {
+			created = created || (action.Name == "tag" && action.Status == actionPerformed)
+		}
+		if created {
+			released = append(released, release.Tag)
+		}
+		outputs[prefix+"released"] = fmt.Sprint(created)
+		outputs[prefix+"version"] = release.NextVersion
+		outputs[prefix+"tag"] = release.Tag
+		outputs[prefix+"previous_version"] = release.PreviousVersion
+		outputs[prefix+"previous_tag"] = release.PreviousTag
+		outputs[prefix+"bump"] = release.Bump
+		outputs[prefix+"prerelease"] = fmt.Sprint(release.Prerelease)
+	}
+	outputs["released_tags"] = strings.Join(released, " ")
+	return outputs
+}
+
+// ciVariableChars matches the characters that are not allowed in output variable names.
+var ciVariableChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeCIOutputs passes the results of a release run on to later steps of the CI pipeline: as
+// step outputs on GitHub Actions and Azure Pipelines, and as a dotenv file (ci_output_file) that
+// GitLab CI loads with artifacts:reports:dotenv and other systems can source.
+func writeCIOutputs(cfg *Config, r *runReport) {
+	if cfg.CIEnv.Provider == "" || !containsString([]string{"release", "release-all", "promote"}, r.Command) {
+		return
+	}
+	outputs := ciOutputs(r)
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch cfg.CIEnv.Provider {
+	case "github":
+		if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+			var sb strings.Builder
+			for _, key := range keys {
+				fmt.Fprintf(&sb, "%s=%s\n", key, outputs[key])
+			}
+			if err := appendFile(path, sb.String()); err != nil {
+				fmt.Printf("Warning: could not write the GitHub Actions outputs: %v\n", err)
+			}
+		}
+	case "azure":
+		for _, key := range keys {
+			fmt.Printf("##vso[task.setvariable variable=%s;isOutput=true]%s\n", key, outputs[key])
+		}
+	}
+
+	path := cfg.CIOutputFile
+	if path == "" && cfg.CIEnv.Provider == "gitlab" {
+		path = "semvergo.env"
+	}
+	if path == "" {
+		return
+	}
+	var sb strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "%s%s=%s\n", envPrefix, strings.ToUpper(key), outputs[key])
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		fmt.Printf("Warning: could not write the CI output file %s: %v\n", path, err)
+		return
+	}
+	// Later SemVerGo steps of the job would fail the git status check on the untracked file
+	if err := excludeFromWorktree(path); err != nil {
+		fmt.Printf("Warning: could not exclude %s from the git status, add it to .gitignore: %v\n", path, err)
+	}
+}
+
+// excludeFromWorktree adds a file in the worktree to .git/info/exclude of the clone, unless
+// it is outside the worktree or already ignored.
+func excludeFromWorktree(file string) error {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(strings.TrimSpace(string(out)), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil // Not in the worktree
+	}
+	if exec.Command("git", "check-ignore", "-q", "--", file).Run() == nil {
+		return nil
+	}
+
+	out, err = exec.Command("git", "rev-parse", "--git-path", "info/exclude").Output()
+	if err != nil {
+		return err
+	}
+	excludePath := strings.TrimSpace(string(out))
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	entry := "/" + filepath.ToSlash(rel) + "\n"
+	if existing, err := os.ReadFile(excludePath); err == nil && len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		entry = "\n" + entry
+	}
+	return appendFile(excludePath, entry)
+}
+
+// appendFile appends content to a file, creating it if needed.
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// validateCI checks the CI integration settings.
+func (c *Config) validateCI() []configError {
+	if c.CIProvider != "auto" && c.CIProvider != "none" && !containsString(ciProviders, c.CIProvider) {
+		return []configError{{Key: "ci_provider", Message: fmt.Sprintf("invalid provider %q, must be one of auto, none, %s", c.CIProvider, strings.Join(ciProviders, ", "))}}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Footer is a single git trailer style footer of a commit message, e.g. "Refs: #123"
+// or "BREAKING CHANGE: drop support for Go 1.20".
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Commit is the structured representation of a single commit following the
+// Conventional Commits 1.0 specification.
+// See: https://www.conventionalcommits.org/en/v1.0.0/#specification
+type Commit struct {
+	SHA    string
+	Author string
+	Email  string // Author email
+	Date   time.Time
+
+	Message string // Full commit message
+	Header  string // First line of the message
+	Type    string
+	Scope   string
+	Subject string
+	Body    string
+	Footers []Footer
+
+	Breaking     bool
+	Conventional bool // Header matches the Conventional Commits format
+	Merge        bool // Merge commit created by git ("Merge branch ...")
+}
+
+// footerPattern matches the start of a footer line. Tokens use '-' in place of whitespace,
+// with the exception of "BREAKING CHANGE".
+var footerPattern = regexp.MustCompile(`^(?P<token>BREAKING CHANGE|[A-Za-z0-9][A-Za-z0-9-]*)(?:: | #)(?P<value>.*)$`)
+
+// parseCommit parses a raw commit message into a Commit.
+// Only the message based fields are set; SHA, author and date are filled in by the caller.
+func parseCommit(message string) Commit {
+	message = strings.ReplaceAll(strings.TrimSpace(message), "\r\n", "\n")
+	lines := strings.Split(message, "\n")
+
+	commit := Commit{Message: message, Header: strings.TrimSpace(lines[0])}
+	commit.Merge = strings.HasPrefix(commit.Header, "Merge ")
+
+	if matches := commitPattern.FindStringSubmatch(commit.Header); len(matches) > 0 {
+		commit.Conventional = true
+		commit.Type = matches[commitPattern.SubexpIndex("type")]
+		commit.Scope = matches[commitPattern.SubexpIndex("scope")]
+		commit.Subject = matches[commitPattern.SubexpIndex("subject")]
+		commit.Breaking = matches[commitPattern.SubexpIndex("breaking")] != ""
+	} else {
+		commit.Subject = commit.Header
+	}
+
+	// Everything after the header (the spec requires a blank line in between) is body and footers
+	rest := lines[1:]
+	for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+		rest = rest[1:]
+	}
+
+	footerStart := findFooterStart(rest)
+	commit.Body = strings.TrimSpace(strings.Join(rest[:footerStart], "\n"))
+	commit.Footers = parseFooters(rest[footerStart:])
+
+	for _, footer := range commit.Footers {
+		if isBreakingToken(footer.Token) {
+			commit.Breaking = true
+		}
+	}
+
+	return commit
+}
+
+// findFooterStart returns the index of the first line of the footer block.
+// The footer block is the trailing run of paragraphs that each start with a footer token.
+// If there are no footers, len(lines) is returned.
+func findFooterStart(lines []string) int {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		// Only consider lines that begin a paragraph
+		if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			continue
+		}
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if !footerPattern.MatchString(lines[i]) {
+			break
+		}
+		start = i
+	}
+	return start
+}
+
+// parseFooters parses a footer block. Lines that do not start a new footer are
+// continuation lines and belong to the value of the previous footer.
+func parseFooters(lines []string) []Footer {
+	var footers []Footer
+	for _, line := range lines {
+		if matches := footerPattern.FindStringSubmatch(line); len(matches) > 0 {
+			footers = append(footers, Footer{
+				Token: matches[footerPattern.SubexpIndex("token")],
+				Value: matches[footerPattern.SubexpIndex("value")],
+			})
+			continue
+		}
+		if len(footers) > 0 {
+			footers[len(footers)-1].Value += "\n" + line
+		}
+	}
+	for i := range footers {
+		footers[i].Value = strings.TrimSpace(footers[i].Value)
+	}
+	return footers
+}
+
+// isBreakingToken reports whether a footer token announces a breaking change.
+// The spec allows both "BREAKING CHANGE" and "BREAKING-CHANGE".
+func isBreakingToken(token string) bool {
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
+
+// FooterValue returns the value of the first footer with the given token (case insensitive).
+func (c Commit) FooterValue(token string) (string, bool) {
+	for _, footer := range c.Footers {
+		if strings.EqualFold(footer.Token, token) {
+			return footer.Value, true
+		}
+	}
+	return "", false
+}
+
+// BreakingDescription returns the description given in a BREAKING CHANGE footer, if any.
+func (c Commit) BreakingDescription() string {
+	for _, footer := range c.Footers {
+		if isBreakingToken(footer.Token) {
+			return footer.Value
+		}
+	}
+	return ""
+}
+
+// shortSHA returns the abbreviated form of a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Commit // Only the parsed fields, Message is not compared
+	}{
+		{
+			name:    "header only",
+			message: "feat(api): add login",
+			want:    Commit{Header: "feat(api): add login", Type: "feat", Scope: "api", Subject: "add login", Conventional: true},
+		},
+		{
+			name:    "breaking marker",
+			message: "feat(api)!: drop v1",
+			want:    Commit{Header: "feat(api)!: drop v1", Type: "feat", Scope: "api", Subject: "drop v1", Breaking: true, Conventional: true},
+		},
+		{
+			name:    "breaking marker without scope",
+			message: "refactor!: rename the config keys",
+			want:    Commit{Header: "refactor!: rename the config keys", Type: "refactor", Subject: "rename the config keys", Breaking: true, Conventional: true},
+		},
+		{
+			name:    "BREAKING CHANGE footer",
+			message: "feat: new API\n\nBREAKING CHANGE: the old API is removed",
+			want: Commit{Header: "feat: new API", Type: "feat", Subject: "new API", Breaking: true, Conventional: true,
+				Footers: []Footer{{Token: "BREAKING CHANGE", Value: "the old API is removed"}}},
+		},
+		{
+			name:    "BREAKING-CHANGE footer",
+			message: "fix: parse dates as UTC\n\nBREAKING-CHANGE: local times are no longer accepted",
+			want: Commit{Header: "fix: parse dates as UTC", Type: "fix", Subject: "parse dates as UTC", Breaking: true, Conventional: true,
+				Footers: []Footer{{Token: "BREAKING-CHANGE", Value: "local times are no longer accepted"}}},
+		},
+		{
+			name:    "hash footers",
+			message: "fix: crash on empty input\n\nFixes #123\nReviewed-by: Jane Doe\nRefs: #7",
+			want: Commit{Header: "fix: crash on empty input", Type: "fix", Subject: "crash on empty input", Conventional: true,
+				Footers: []Footer{{Token: "Fixes", Value: "123"}, {Token: "Reviewed-by", Value: "Jane Doe"}, {Token: "Refs", Value: "#7"}}},
+		},
+		{
+			name:    "continuation lines",
+			message: "feat: new API\n\nBREAKING CHANGE: the old API is removed,\nmigrate to the new one\n  as described in the docs\nRefs: #7",
+			want: Commit{Header: "feat: new API", Type: "feat", Subject: "new API", Breaking: true, Conventional: true,
+				Footers: []Footer{{Token: "BREAKING CHANGE", Value: "the old API is removed,\nmigrate to the new one\n  as described in the docs"}, {Token: "Refs", Value: "#7"}}},
+		},
+		{
+			name:    "body with several paragraphs",
+			message: "fix: retry pushes\n\nPushes fail on flaky networks.\n\nThey are retried twice\nbefore giving up.\n\nRefs: #12",
+			want: Commit{Header: "fix: retry pushes", Type: "fix", Subject: "retry pushes", Conventional: true,
+				Body:    "Pushes fail on flaky networks.\n\nThey are retried twice\nbefore giving up.",
+				Footers: []Footer{{Token: "Refs", Value: "#12"}}},
+		},
+		{
+			name:    "footer blocks of several paragraphs",
+			message: "fix: x\n\nBody.\n\nRefs: #1\n\nReviewed-by: Z",
+			want: Commit{Header: "fix: x", Type: "fix", Subject: "x", Conventional: true, Body: "Body.",
+				Footers: []Footer{{Token: "Refs", Value: "#1"}, {Token: "Reviewed-by", Value: "Z"}}},
+		},
+		{
+			name:    "BREAKING CHANGE as prose in the body",
+			message: "docs: explain compatibility\n\nThis release avoids a\nBREAKING CHANGE: by keeping the old flags.\n\nRefs: #3",
+			want: Commit{Header: "docs: explain compatibility", Type: "docs", Subject: "explain compatibility", Conventional: true,
+				Body:    "This release avoids a\nBREAKING CHANGE: by keeping the old flags.",
+				Footers: []Footer{{Token: "Refs", Value: "#3"}}},
+		},
+		{
+			name:    "BREAKING CHANGE as prose in the last paragraph",
+			message: "docs: explain compatibility\n\nThis release avoids a\nBREAKING CHANGE: by keeping the old flags.",
+			want: Commit{Header: "docs: explain compatibility", Type: "docs", Subject: "explain compatibility", Conventional: true,
+				Body: "This release avoids a\nBREAKING CHANGE: by keeping the old flags."},
+		},
+		{
+			name:    "footer like line followed by a body paragraph",
+			message: "fix: x\n\nNote: this is part of the body.\n\nMore body.",
+			want:    Commit{Header: "fix: x", Type: "fix", Subject: "x", Conventional: true, Body: "Note: this is part of the body.\n\nMore body."},
+		},
+		{
+			name:    "CRLF",
+			message: "feat: new API\r\n\r\nBody line one\r\nline two\r\n\r\nBREAKING CHANGE: removed v1\r\nRefs: #7\r\n",
+			want: Commit{Header: "feat: new API", Type: "feat", Subject: "new API", Breaking: true, Conventional: true,
+				Body:    "Body line one\nline two",
+				Footers: []Footer{{Token: "BREAKING CHANGE", Value: "removed v1"}, {Token: "Refs", Value: "#7"}}},
+		},
+		{
+			name:    "not conventional",
+			message: "Update README",
+			want:    Commit{Header: "Update README", Subject: "Update README"},
+		},
+		{
+			name:    "unknown type",
+			message: "feature: add login",
+			want:    Commit{Header: "feature: add login", Subject: "feature: add login"},
+		},
+		{
+			name:    "merge commit",
+			message: "Merge branch 'feature/x' into main",
+			want:    Commit{Header: "Merge branch 'feature/x' into main", Subject: "Merge branch 'feature/x' into main", Merge: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCommit(tt.message)
+			got.Message = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommit() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakingDescription(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{message: "feat!: x", want: ""},
+		{message: "feat: x\n\nBREAKING CHANGE: y", want: "y"},
+		{message: "feat: x\n\nRefs: #1\nBREAKING-CHANGE: y\nz", want: "y\nz"},
+	}
+	for _, tt := range tests {
+		if got := parseCommit(tt.message).BreakingDescription(); got != tt.want {
+			t.Errorf("BreakingDescription() of %q = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are the project configuration files looked up at the repository root, in order.
+var configFileNames = []string{".semvergo.yaml", ".semvergo.yml", ".semvergo.toml"}
+
+// envPrefix is the prefix of environment variables overriding configuration values.
+// A key such as changelog.path maps to SEMVERGO_CHANGELOG_PATH.
+const envPrefix = "SEMVERGO_"
+
+// Config holds the effective configuration of a run.
+// Values are resolved with the precedence flag > environment > config file > default.
+type Config struct {
+	Branch             string              `yaml:"branch" toml:"branch"`
+	PreRelease         bool                `yaml:"pre_release" toml:"pre_release"`
+	CI                 bool                `yaml:"ci" toml:"ci"`
+	CIProvider         string              `yaml:"ci_provider" toml:"ci_provider"`       // auto, none or one of ciProviders
+	CIOutputFile       string              `yaml:"ci_output_file" toml:"ci_output_file"` // dotenv file of the results, default semvergo.env on GitLab CI
+	PushBranch         bool                `yaml:"push_branch" toml:"push_branch"`
+	SkipChecks         bool                `yaml:"skip_checks" toml:"skip_checks"`
+	DryRun             bool                `yaml:"dry_run" toml:"dry_run"`
+	Debug              bool                `yaml:"debug" toml:"debug"`
+	Remote             string              `yaml:"remote" toml:"remote"`
+	TagPrefix          string              `yaml:"tag_prefix" toml:"tag_prefix"` // Tags are named <tag_prefix><version> unless tag_format is set
+	TagFormat          string              `yaml:"tag_format" toml:"tag_format"`
+	TagMessage         string              `yaml:"tag_message" toml:"tag_message"`
+	CommitMessage      string              `yaml:"release_commit_message" toml:"release_commit_message"`
+	FirstParent        bool                `yaml:"first_parent" toml:"first_parent"`               // Only tags on the first-parent history of HEAD count
+	Scheme             string              `yaml:"scheme" toml:"scheme"`                           // semver or calver
+	InitialDevelopment bool                `yaml:"initial_development" toml:"initial_development"` // Lower bumps by one level while the major version is 0
+	CalVer             CalVerConfig        `yaml:"calver" toml:"calver"`
+	Types              []string            `yaml:"types" toml:"types"`
+	BumpRules          []BumpRule          `yaml:"bump_rules" toml:"bump_rules"`
+	SkipCIPatterns     []string            `yaml:"skip_ci_patterns" toml:"skip_ci_patterns"`
+	Changelog          ChangelogConfig     `yaml:"changelog" toml:"changelog"`
+	VersionFiles       []VersionFileConfig `yaml:"version_files,omitempty" toml:"version_files,omitempty"`
+	Packages           []PackageConfig     `yaml:"packages,omitempty" toml:"packages,omitempty"`
+	GoModule           GoModuleConfig      `yaml:"go_module" toml:"go_module"`
+	Channels           []ChannelConfig     `yaml:"channels,omitempty" toml:"channels,omitempty"`
+	Maintenance        []MaintenanceConfig `yaml:"maintenance,omitempty" toml:"maintenance,omitempty"`
+
+	// Source is the config file the configuration was loaded from, empty if none was found.
+	Source string `yaml:"-" toml:"-"`
+	// PrereleaseID is the pre-release identifier resolved from the branch and channels by prepareRelease.
+	PrereleaseID string `yaml:"-" toml:"-"`
+	// VersionRange is the version range of the maintenance branch being released, resolved by prepareRelease.
+	VersionRange string `yaml:"-" toml:"-"`
+	// CIEnv is the build of the CI system SemVerGo runs in, detected when the configuration is loaded.
+	CIEnv ciEnvironment `yaml:"-" toml:"-"`
+}
+
+// ChangelogConfig holds the changelog related settings.
+type ChangelogConfig struct {
+	Enabled       bool   `yaml:"enabled" toml:"enabled"`
+	Path          string `yaml:"path" toml:"path"`
+	CommitMessage string `yaml:"commit_message" toml:"commit_message"`
+	Template      string `yaml:"template" toml:"template"` // Path to a text/template file rendering the release notes, empty for the default
+	Format        string `yaml:"format" toml:"format"`     // default or keepachangelog
+
+	CommitLinks bool              `yaml:"commit_links" toml:"commit_links"`                 // Add the short SHA of the commit to each entry
+	Authors     bool              `yaml:"authors" toml:"authors"`                           // Credit the author of each entry
+	IssueURL    string            `yaml:"issue_url" toml:"issue_url"`                       // URL of issue {{.ID}} for "#123" references, empty to derive it from the remote
+	References  []ReferenceConfig `yaml:"references,omitempty" toml:"references,omitempty"` // Keys of other issue trackers to link
+
+	Sections     []ChangelogSectionConfig `yaml:"sections,omitempty" toml:"sections,omitempty"` // Replace the sections of the format
+	GroupByScope bool                     `yaml:"group_by_scope" toml:"group_by_scope"`         // List the commits of a section in blocks per scope
+}
+
+// BumpRule maps commits to a version bump. Rules are evaluated in order and the first
+// matching rule decides the bump of a commit; commits matching no rule do not bump the version.
+// Breaking changes always result in a major bump.
+type BumpRule struct {
+	Type   string `yaml:"type,omitempty" toml:"type,omitempty"`     // Commit type, "*" matches any type
+	Scope  string `yaml:"scope,omitempty" toml:"scope,omitempty"`   // Glob matched against the scope, empty matches any scope
+	Footer string `yaml:"footer,omitempty" toml:"footer,omitempty"` // Footer token the commit must carry, e.g. "Security"
+	Bump   string `yaml:"bump" toml:"bump"`                         // major, minor, patch or none
+}
+
+// bumpLevels ranks the bump types, higher wins.
+var bumpLevels = map[string]int{"none": 0, "patch": 1, "minor": 2, "major": 3}
+
+// defaultConfig returns the configuration used when nothing else is specified.
+func defaultConfig() *Config {
+	return &Config{
+		CIProvider:         "auto",
+		Remote:             "origin",
+		TagPrefix:          "v",
+		TagMessage:         "Release {{.Tag}} [skip-ci]",
+		CommitMessage:      "chore(release): {{.Tag}} [skip-ci]",
+		Scheme:             "semver",
+		InitialDevelopment: true,
+		Types:              []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+		BumpRules: []BumpRule{
+			{Type: "feat", Bump: "minor"},
+			{Type: "fix", Bump: "patch"},
+		},
+		SkipCIPatterns: []string{
+			"[skip-ci]",
+			"[ci skip]",
+			"skip-checks: true",
+		},
+		Changelog: ChangelogConfig{
+			Path:          "CHANGELOG.md",
+			CommitMessage: "chore(release): update changelog for {{.Tag}} [skip-ci]",
+			Format:        "default",
+			CommitLinks:   true,
+		},
+		GoModule: GoModuleConfig{
+			MajorCheck: "error",
+		},
+		CalVer: CalVerConfig{
+			Format: "YYYY.MM.MICRO",
+		},
+	}
+}
+
+// flagConfigKeys maps command-line flags to the configuration keys they override.
+var flagConfigKeys = map[string]string{
+	"branch":           "branch",
+	"preRelease":       "pre_release",
+	"ci":               "ci",
+	"push-branch":      "push_branch",
+	"skip-checks":      "skip_checks",
+	"dry-run":          "dry_run",
+	"debug":            "debug",
+	"tag-format":       "tag_format",
+	"first-parent":     "first_parent",
+	"output-changelog": "changelog.enabled",
+}
+
+// configError is a validation error of a single configuration key.
+type configError struct {
+	Key     string
+	Message string
+}
+
+// loadConfig builds the effective configuration from the defaults, the project config file,
+// the environment and the flags explicitly set on the command line.
+// If configPath is empty, the config file is looked up at the root of the current repository.
+func loadConfig(configPath string, flags *flag.FlagSet) (*Config, error) {
+	cfg := defaultConfig()
+
+	if configPath == "" {
+		var err error
+		configPath, err = findConfigFile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if configPath != "" {
+		file, err := readConfigFile(configPath, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if errs := cfg.validate(); len(errs) > 0 {
+			return nil, file.formatErrors(errs)
+		}
+		cfg.Source = configPath
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		key, ok := flagConfigKeys[f.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		if err := setConfigValue(cfg, key, f.Value.String()); err != nil {
+			flagErr = fmt.Errorf("invalid value for flag -%s: %v", f.Name, err)
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if errs := cfg.validate(); len(errs) > 0 {
+		var msgs []string
+		for _, e := range errs {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", e.Key, e.Message))
+		}
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(msgs, "\n  "))
+	}
+
+	setCommitTypes(cfg.Types)
+	cfg.CIEnv = detectCI(cfg)
+	return cfg, nil
+}
+
+// findConfigFile looks for a config file at the root of the current Git repository.
+// It returns an empty path if there is none.
+func findConfigFile() (string, error) {
+	root := "."
+	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		root = strings.TrimSpace(string(out))
+	}
+
+	var found []string
+	for _, name := range configFileNames {
+		path := filepath.Join(root, name)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+
+	if len(found) > 1 {
+		return "", fmt.Errorf("multiple config files found (%s). Please keep only one", strings.Join(found, ", "))
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	return "", nil
+}
+
+// configFile is a parsed config file, used to point validation errors at their line.
+type configFile struct {
+	path  string
+	yaml  *yaml.Node     // Set for YAML files
+	lines map[string]int // Key path to line number, set for TOML files
+}
+
+// readConfigFile decodes the config file at path into cfg, rejecting unknown keys.
+func readConfigFile(path string, cfg *Config) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file '%s': %v", path, err)
+	}
+
+	file := &configFile{path: path}
+	if strings.HasSuffix(path, ".toml") {
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			if parseErr, ok := err.(toml.ParseError); ok {
+				return nil, fmt.Errorf("%s:%d: %s", path, parseErr.Position.Line, parseErr.Message)
+			}
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		file.lines = tomlKeyLines(data)
+		var errs []configError
+		for _, key := range md.Undecoded() {
+			errs = append(errs, configError{Key: key.String(), Message: "unknown configuration key"})
+		}
+		if len(errs) > 0 {
+			return nil, file.formatErrors(errs)
+		}
+		return file, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	file.yaml = &root
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %v", path, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	return file, nil
+}
+
+// formatErrors turns validation errors into a single error pointing at the offending lines.
+func (f *configFile) formatErrors(errs []configError) error {
+	var msgs []string
+	for _, e := range errs {
+		if line := f.line(e.Key); line > 0 {
+			msgs = append(msgs, fmt.Sprintf("%s:%d: %s: %s", f.path, line, e.Key, e.Message))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("%s: %s: %s", f.path, e.Key, e.Message))
+		}
+	}
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+// line returns the line a key path (e.g. "changelog.path" or "types.2") is defined on, or 0.
+func (f *configFile) line(key string) int {
+	if f.lines != nil {
+		return f.lines[key]
+	}
+	if f.yaml == nil || len(f.yaml.Content) == 0 {
+		return 0
+	}
+
+	node := f.yaml.Content[0]
+	line := 0
+	for _, part := range strings.Split(key, ".") {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(part); err == nil && index < len(node.Content) {
+				next = node.Content[index]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line // Closest parent that exists
+		}
+		node = next
+	}
+	return line
+}
+
+var (
+	tomlTablePattern = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(?:#.*)?$`)
+	tomlArrayPattern = regexp.MustCompile(`^\s*\[\[\s*([^\[\]]+?)\s*\]\]\s*(?:#.*)?$`)
+	tomlKeyPattern   = regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+)\s*=`)
+	tomlIndexPattern = regexp.MustCompile(`\.\d+`)
+)
+
+// tomlKeyLines maps the key paths of a TOML document to their line numbers.
+// Entries of arrays of tables are indexed, e.g. "packages.1.name".
+func tomlKeyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	arrayCounts := map[string]int{}
+	table := ""
+	for i, text := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		if matches := tomlArrayPattern.FindStringSubmatch(text); matches != nil {
+			name := strings.TrimSpace(matches[1])
+			table = fmt.Sprintf("%s.%d", name, arrayCounts[name])
+			arrayCounts[name]++
+			lines[table] = lineNo
+			if _, ok := lines[name]; !ok {
+				lines[name] = lineNo
+			}
+			continue
+		}
+		if matches := tomlTablePattern.FindStringSubmatch(text); matches != nil {
+			table = strings.TrimSpace(matches[1])
+			lines[table] = lineNo
+			continue
+		}
+		if matches := tomlKeyPattern.FindStringSubmatch(text); matches != nil {
+			key := matches[1]
+			if table != "" {
+				key = table + "." + key
+			}
+			lines[key] = lineNo
+			// Undecoded keys are reported without array indices, point those at the first occurrence
+			if plain := tomlIndexPattern.ReplaceAllString(key, ""); plain != key {
+				if _, ok := lines[plain]; !ok {
+					lines[plain] = lineNo
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// commitTypePattern restricts commit type names to what the header pattern can match.
+var commitTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// validate checks the configuration for values that cannot work.
+func (c *Config) validate() []configError {
+	var errs []configError
+
+	if strings.TrimSpace(c.Remote) == "" {
+		errs = append(errs, configError{Key: "remote", Message: "must not be empty"})
+	}
+	if len(c.Types) == 0 {
+		errs = append(errs, configError{Key: "types", Message: "at least one commit type is required"})
+	}
+	for i, t := range c.Types {
+		if !commitTypePattern.MatchString(t) {
+			errs = append(errs, configError{Key: fmt.Sprintf("types.%d", i), Message: fmt.Sprintf("invalid commit type %q", t)})
+		}
+	}
+	for i, rule := range c.BumpRules {
+		key := fmt.Sprintf("bump_rules.%d", i)
+		if rule.Type == "" && rule.Footer == "" {
+			errs = append(errs, configError{Key: key, Message: "a rule needs a type or a footer"})
+		}
+		if rule.Type != "" && rule.Type != "*" && !containsString(c.Types, rule.Type) {
+			errs = append(errs, configError{Key: key + ".type", Message: fmt.Sprintf("commit type %q is not listed in types", rule.Type)})
+		}
+		if _, err := path.Match(rule.Scope, ""); err != nil {
+			errs = append(errs, configError{Key: key + ".scope", Message: fmt.Sprintf("invalid scope pattern %q", rule.Scope)})
+		}
+		if _, ok := bumpLevels[rule.Bump]; !ok {
+			errs = append(errs, configError{Key: key + ".bump", Message: fmt.Sprintf("invalid bump %q, must be one of major, minor, patch, none", rule.Bump)})
+		}
+	}
+	if strings.TrimSpace(c.Changelog.Path) == "" {
+		errs = append(errs, configError{Key: "changelog.path", Message: "must not be empty"})
+	}
+	if c.Changelog.Enabled && strings.TrimSpace(c.Changelog.CommitMessage) == "" {
+		errs = append(errs, configError{Key: "changelog.commit_message", Message: "must not be empty when the changelog is enabled"})
+	}
+	if format, ok := changelogFormats[c.Changelog.Format]; !ok {
+		errs = append(errs, configError{Key: "changelog.format", Message: fmt.Sprintf("invalid format %q, must be one of default, keepachangelog", c.Changelog.Format)})
+	} else if c.Changelog.Template != "" {
+		if _, err := parseChangelogTemplate(c.Changelog.Template, format.template); err != nil {
+			errs = append(errs, configError{Key: "changelog.template", Message: err.Error()})
+		}
+	}
+	if c.Changelog.IssueURL != "" && !strings.Contains(c.Changelog.IssueURL, "{{.ID}}") {
+		errs = append(errs, configError{Key: "changelog.issue_url", Message: "must contain the {{.ID}} placeholder"})
+	}
+	errs = append(errs, validateReferences(c.Changelog.References)...)
+	errs = append(errs, c.validateChangelogSections()...)
+	if _, err := parseTagFormat(c.TagFormat); err != nil {
+		errs = append(errs, configError{Key: "tag_format", Message: fmt.Sprintf("invalid template: %v", err)})
+	}
+	if strings.TrimSpace(c.TagMessage) == "" {
+		errs = append(errs, configError{Key: "tag_message", Message: "must not be empty"})
+	}
+	if strings.TrimSpace(c.CommitMessage) == "" {
+		errs = append(errs, configError{Key: "release_commit_message", Message: "must not be empty"})
+	}
+	switch c.GoModule.MajorCheck {
+	case "error", "warn", "off":
+	default:
+		errs = append(errs, configError{Key: "go_module.major_check", Message: fmt.Sprintf("invalid value %q, must be one of error, warn, off", c.GoModule.MajorCheck)})
+	}
+	errs = append(errs, c.validateScheme()...)
+	errs = append(errs, validateVersionFiles(c.VersionFiles, "version_files")...)
+	errs = append(errs, c.validatePackages()...)
+	errs = append(errs, c.validateChannels()...)
+	errs = append(errs, c.validateMaintenance()...)
+	errs = append(errs, c.validateCI()...)
+
+	return errs
+}
+
+// applyEnv overrides configuration values from SEMVERGO_* environment variables.
+func applyEnv(cfg *Config) error {
+	for _, key := range configKeys(reflect.TypeOf(*cfg), "") {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setConfigValue(cfg, key, value); err != nil {
+			return fmt.Errorf("invalid value for environment variable %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// configKeys lists the keys of all settings that can be set from a single string value.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key+".")...)
+		case reflect.String, reflect.Bool, reflect.Int:
+			keys = append(keys, key)
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.String {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setConfigValue sets the setting identified by a dotted key from its string representation.
+// Lists are given as comma separated values.
+func setConfigValue(cfg *Config, key, value string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown configuration key %s", key)
+		}
+		field, ok := fieldByYAMLName(v, part)
+		if !ok {
+			return fmt.Errorf("unknown configuration key %s", key)
+		}
+		v = field
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%s cannot be set from a single value", key)
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s cannot be set from a single value", key)
+	}
+	return nil
+}
+
+// fieldByYAMLName returns the field of a struct value with the given configuration key name.
+func fieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if yamlName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// yamlName returns the configuration key name of a struct field, or "" if it is not a setting.
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// runConfigCommand implements the "config" command.
+func runConfigCommand(cfg *Config, args []string) error {
+	if len(args) == 0 || args[0] != "show" {
+		return withCode(errCodeUsage, fmt.Errorf("usage: semvergo [flags] config show"))
+	}
+
+	if cfg.Source != "" {
+		fmt.Printf("# Effective configuration (config file: %s)\n", cfg.Source)
+	} else {
+		fmt.Println("# Effective configuration (no config file found, using defaults)")
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("error encoding configuration: %v", err)
+	}
+
+	// The JSON report uses the keys of the config file
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("error encoding configuration: %v", err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("error encoding configuration: %v", err)
+	}
+	report.Config = values
+	return encoder.Close()
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// renderMessage fills in the {{.Tag}} placeholder of a configured tag or commit message.
+func renderMessage(message, tagName string) string {
+	return strings.ReplaceAll(message, "{{.Tag}}", tagName)
+}
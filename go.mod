@@ -2,4 +2,8 @@ module github.com/emrefirat/SemVerGo
 
 go 1.22.2
 
-require github.com/Masterminds/semver/v3 v3.3.1 // indirect
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/Masterminds/semver/v3 v3.3.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// GoModuleConfig controls the handling of Go modules found in a release target.
+// See: https://go.dev/ref/mod#major-version-suffixes
+type GoModuleConfig struct {
+	MajorCheck string `yaml:"major_check" toml:"major_check"` // error, warn or off
+	Rewrite    bool   `yaml:"rewrite" toml:"rewrite"`         // Rewrite the module path and imports on a major bump
+}
+
+// goModulePattern matches the module directive of a go.mod file.
+var goModulePattern = regexp.MustCompile(`(?m)^module\s+("?)([^"\s]+)("?)\s*$`)
+
+// goMajorSuffixPattern matches the major version suffix of a module path, e.g. "/v2".
+var goMajorSuffixPattern = regexp.MustCompile(`/v([0-9]+)$`)
+
+// goModule is the Go module at the root directory of a release target.
+type goModule struct {
+	Dir         string   // Module directory relative to the repository root, "." for the root
+	Path        string   // Module path declared in go.mod
+	NestedDirs  []string // Directories of nested modules, which are versioned on their own
+	NestedPaths []string // Module paths of the nested modules, in the order of NestedDirs
+}
+
+// loadGoModule reads the go.mod in the target directory. It returns nil if there is none
+// or Go module handling is disabled.
+func loadGoModule(cfg *Config, target releaseTarget) (*goModule, error) {
+	if cfg.GoModule.MajorCheck == "off" {
+		return nil, nil
+	}
+
+	dir := target.Dir
+	if dir == "" {
+		dir = "."
+	}
+	modulePath, err := readModulePath(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	mod := &goModule{Dir: dir, Path: modulePath}
+	mod.NestedDirs, err = nestedModuleDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, nested := range mod.NestedDirs {
+		nestedPath, err := readModulePath(nested)
+		if err != nil {
+			return nil, err
+		}
+		mod.NestedPaths = append(mod.NestedPaths, nestedPath)
+	}
+	return mod, nil
+}
+
+// readModulePath returns the module path declared in the go.mod of dir. The error satisfies
+// os.IsNotExist if there is no go.mod.
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", err
+		}
+		return "", fmt.Errorf("error reading %s: %v", path.Join(dir, "go.mod"), err)
+	}
+
+	matches := goModulePattern.FindSubmatch(data)
+	if matches == nil {
+		return "", fmt.Errorf("no module directive found in %s", path.Join(dir, "go.mod"))
+	}
+	return string(matches[2]), nil
+}
+
+// nestedModuleDirs lists the directories below dir that contain a go.mod of their own.
+func nestedModuleDirs(dir string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == dir {
+			return nil
+		}
+		if skipGoDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+			dirs = append(dirs, filepath.ToSlash(p))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error looking for nested Go modules: %v", err)
+	}
+	return dirs, nil
+}
+
+// skipGoDir reports whether a directory is ignored by the go command.
+func skipGoDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// excludePaths returns path globs excluding the nested modules from the commits of this module.
+func (m *goModule) excludePaths() []string {
+	excludes := make([]string, 0, len(m.NestedDirs))
+	for _, dir := range m.NestedDirs {
+		excludes = append(excludes, "!"+dir+"/**")
+	}
+	return excludes
+}
+
+// majorPath returns the module path the given major version requires.
+func (m *goModule) majorPath(major uint64) string {
+	base := goMajorSuffixPattern.ReplaceAllString(m.Path, "")
+	if major < 2 {
+		return base
+	}
+	return fmt.Sprintf("%s/v%d", base, major)
+}
+
+// tagPrefix returns the tag prefix the Go proxy expects for versions of this module.
+func (m *goModule) tagPrefix() string {
+	if m.Dir == "." {
+		return "v"
+	}
+	return m.Dir + "/v"
+}
+
+// checkMajorVersion verifies that the module path matches the major version of newVersion.
+// It returns the module path the version requires if the module path has to be rewritten,
+// or an empty string if nothing needs to change.
+func (m *goModule) checkMajorVersion(cfg *Config, newVersion string) (string, error) {
+	v, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing version '%s': %v", newVersion, err)
+	}
+
+	required := m.majorPath(v.Major())
+	if required == m.Path {
+		return "", nil
+	}
+
+	if cfg.GoModule.Rewrite {
+		return required, nil
+	}
+
+	msg := fmt.Sprintf("version %s requires the module path %s in %s, but it declares %s. Update the module path and its imports, or set go_module.rewrite: true to let SemVerGo do it",
+		newVersion, required, path.Join(m.Dir, "go.mod"), m.Path)
+	if cfg.GoModule.MajorCheck == "warn" {
+		fmt.Printf("Warning: %s.\n", msg)
+		return "", nil
+	}
+	return "", fmt.Errorf("Error: %s (or set go_module.major_check: warn)", msg)
+}
+
+// checkTagName warns if the Go proxy will not resolve a tag as a version of this module.
+func (m *goModule) checkTagName(tagName, newVersion string) {
+	if expected := m.tagPrefix() + newVersion; tagName != expected {
+		fmt.Printf("Warning: tag %s is not a Go module version tag of %s, the Go proxy expects %s.\n", tagName, m.Path, expected)
+	}
+}
+
+// rewriteModulePath changes the module path in go.mod and in the imports of all Go files of
+// the module to newPath. It returns the changed files.
+func (m *goModule) rewriteModulePath(newPath string) ([]string, error) {
+	goModPath := filepath.Join(m.Dir, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod: %v", err)
+	}
+	loc := goModulePattern.FindSubmatchIndex(data)
+	if loc == nil {
+		return nil, fmt.Errorf("no module directive found in %s", goModPath)
+	}
+	data = append(data[:loc[4]:loc[4]], append([]byte(newPath), data[loc[5]:]...)...)
+	if err := os.WriteFile(goModPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing go.mod: %v", err)
+	}
+	changed := []string{filepath.ToSlash(goModPath)}
+
+	err = filepath.WalkDir(m.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != m.Dir && (skipGoDir(d.Name()) || containsString(m.NestedDirs, filepath.ToSlash(p))) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+		ok, err := rewriteImports(p, m.Path, newPath, m.NestedPaths)
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed = append(changed, filepath.ToSlash(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error rewriting imports: %v", err)
+	}
+
+	m.Path = newPath
+	return changed, nil
+}
+
+// rewriteImports replaces imports of oldPath and its packages in a Go file with newPath.
+// Imports of the nested modules below oldPath are left alone, as their paths do not change.
+// It reports whether the file was changed.
+func rewriteImports(file, oldPath, newPath string, nestedPaths []string) (bool, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, spec := range parsed.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importPath != oldPath && !strings.HasPrefix(importPath, oldPath+"/") {
+			continue
+		}
+		if importsNestedModule(importPath, nestedPaths) {
+			continue
+		}
+		edits = append(edits, edit{
+			start: fset.Position(spec.Path.Pos()).Offset,
+			end:   fset.Position(spec.Path.End()).Offset,
+			text:  strconv.Quote(newPath + strings.TrimPrefix(importPath, oldPath)),
+		})
+	}
+	if len(edits) == 0 {
+		return false, nil
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = append(src[:e.start:e.start], append([]byte(e.text), src[e.end:]...)...)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(file, src, info.Mode())
+}
+
+// importsNestedModule reports whether an import path is a package of one of the nested modules.
+func importsNestedModule(importPath string, nestedPaths []string) bool {
+	for _, nested := range nestedPaths {
+		if importPath == nested || strings.HasPrefix(importPath, nested+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+)
+
+// keepAChangelogEntryTemplate renders a single commit in the Keep a Changelog format.
+// It is available to user templates as {{template "kacEntry" .}}.
+const keepAChangelogEntryTemplate = `{{define "kacEntry"}}- {{if .Breaking}}**BREAKING:** {{end}}{{if .Scope}}**{{.Scope}}:** {{end}}{{linkReferences .Subject}}{{template "entryLinks" .}}{{with .BreakingDescription}}
+{{indent 2 .}}{{end}}{{end}}`
+
+// keepAChangelogCommitsTemplate renders the commits of a section in the Keep a Changelog format,
+// see changelogCommitsTemplate. It is available to user templates as {{template "kacCommits" .}}.
+const keepAChangelogCommitsTemplate = `{{define "kacCommits"}}{{if .Scopes}}{{range .Scopes}}{{if .Name}}- **{{.Name}}:**
+{{range .Commits}}  - {{if .Breaking}}**BREAKING:** {{end}}{{linkReferences .Subject}}{{template "entryLinks" .}}{{with .BreakingDescription}}
+{{indent 4 .}}{{end}}
+{{end}}{{else}}{{range .Commits}}{{template "kacEntry" .}}
+{{end}}{{end}}{{end}}{{else}}{{range .Commits}}{{template "kacEntry" .}}
+{{end}}{{end}}{{end}}`
+
+// keepAChangelogTemplate renders the section of a version in the Keep a Changelog format.
+const keepAChangelogTemplate = `## [{{.Version}}] - {{.Date}}
+{{range .Groups}}
+### {{.Title}}
+
+{{range .Notes}}{{.}}
+{{end}}{{template "kacCommits" .}}{{end}}`
+
+// keepAChangelogSections are the sections of the Keep a Changelog format, see
+// https://keepachangelog.com/en/1.1.0/#how. Security, Deprecated and Removed footers
+// (or commit types) take precedence over the commit type.
+var keepAChangelogSections = []ChangelogSectionConfig{
+	{Key: "added", Title: "Added", Types: []string{"feat"}},
+	{Key: "changed", Title: "Changed", Types: []string{"*"}}, // Including non-conventional commits
+	{Key: "deprecated", Title: "Deprecated", Types: []string{"deprecate"}, Footers: []string{"Deprecated"}},
+	{Key: "removed", Title: "Removed", Types: []string{"remove"}, Footers: []string{"Removed"}},
+	{Key: "fixed", Title: "Fixed", Types: []string{"fix"}},
+	{Key: "security", Title: "Security", Types: []string{"security"}, Footers: []string{"Security"}},
+	{Key: "hidden", Types: []string{"docs", "style", "test", "chore"}, Hidden: true},
+}
+
+// keepAChangelogPreamble returns the introduction of a new Keep a Changelog file.
+func keepAChangelogPreamble(cfg *Config) string {
+	versioning := "adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html)"
+	if cfg.Scheme == "calver" {
+		versioning = "uses [Calendar Versioning](https://calver.org)"
+	}
+	return "# Changelog\n\n" +
+		"All notable changes to this project will be documented in this file.\n\n" +
+		"The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),\n" +
+		"and this project " + versioning + "."
+}
+
+// updateKeepAChangelog adds the release notes of a version to a Keep a Changelog file.
+// The entries written by hand in the [Unreleased] section are moved into the new version,
+// the [Unreleased] section is emptied, and the link references at the end are updated.
+// If the version is already in the file, its section is written again, keeping the entries
+// that were moved into it.
+func updateKeepAChangelog(cfg *Config, doc *changelogDocument, commits []Commit, data changelogData) error {
+	if len(doc.Sections) == 0 && strings.TrimSpace(doc.Preamble) == "" {
+		doc.Preamble = keepAChangelogPreamble(cfg)
+	}
+
+	unreleased := doc.section("Unreleased")
+	if unreleased >= 0 {
+		data.Groups = sectionNotes(doc.Sections[unreleased].Text)
+	}
+	if existing := doc.section(data.Version); existing >= 0 {
+		// The entries written by hand are the lines that are not generated from the commits
+		generatedData := data
+		generatedData.Groups = nil
+		generated, err := renderReleaseNotes(cfg, commits, generatedData)
+		if err != nil {
+			return err
+		}
+		data.Groups = append(data.Groups, handWrittenNotes(doc.Sections[existing].Text, generated)...)
+	}
+	section, err := renderReleaseNotes(cfg, commits, data)
+	if err != nil {
+		return err
+	}
+
+	empty := changelogSection{Name: "Unreleased", Text: "## [Unreleased]\n"}
+	if unreleased >= 0 {
+		doc.Sections[unreleased] = empty
+	} else {
+		doc.Sections = append([]changelogSection{empty}, doc.Sections...)
+	}
+	doc.putSection(changelogSection{Name: data.Version, Text: section})
+
+	setKeepAChangelogLinks(doc, repositoryURL(cfg.Remote), data)
+	return nil
+}
+
+// setKeepAChangelogLinks points the link reference of the version to its changes, and the
+// [unreleased] link to the changes since the version. Nothing is set if repoURL is unknown.
+func setKeepAChangelogLinks(doc *changelogDocument, repoURL string, data changelogData) {
+	if repoURL == "" {
+		return
+	}
+	versionURL := compareURL(repoURL, data.PreviousTag, data.Tag)
+	if versionURL == "" {
+		versionURL = tagURL(repoURL, data.Tag)
+	}
+	doc.setLink(data.Version, versionURL)
+	doc.setLink("unreleased", compareURL(repoURL, data.Tag, "HEAD"))
+}
+
+// sectionNotes returns the entries of a section, e.g. [Unreleased], grouped by their "### " headings.
+// Entries before the first heading are treated as changes.
+func sectionNotes(text string) []changelogGroup {
+	var groups []changelogGroup
+	title := "Changed"
+	for _, line := range strings.Split(text, "\n")[1:] { // Skip the heading
+		if strings.HasPrefix(line, "### ") {
+			title = strings.TrimSpace(strings.TrimPrefix(line, "### "))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Title != title {
+			groups = append(groups, changelogGroup{Title: title})
+		}
+		groups[len(groups)-1].Notes = append(groups[len(groups)-1].Notes, line)
+	}
+	return groups
+}
+
+// handWrittenNotes returns the entries of a version section that are not in the generated release notes.
+func handWrittenNotes(text, generated string) []changelogGroup {
+	generatedLines := map[string]bool{}
+	for _, line := range strings.Split(generated, "\n") {
+		generatedLines[line] = true
+	}
+
+	var groups []changelogGroup
+	for _, group := range sectionNotes(text) {
+		var notes []string
+		for _, note := range group.Notes {
+			if !generatedLines[note] {
+				notes = append(notes, note)
+			}
+		}
+		if len(notes) > 0 {
+			group.Notes = notes
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ReferenceConfig links the keys of an issue tracker, e.g. Jira, in the release notes.
+type ReferenceConfig struct {
+	Pattern string `yaml:"pattern" toml:"pattern"` // Regular expression matching a key, e.g. "[A-Z][A-Z0-9]+-[0-9]+"
+	URL     string `yaml:"url" toml:"url"`         // URL of a key, {{.ID}} is replaced by the key
+}
+
+// issueReferencePattern matches "#123" references to issues and pull requests. The character
+// before the "#" is captured to leave HTML entities and existing Markdown links alone.
+var issueReferencePattern = regexp.MustCompile(`(^|[^\w&\[/])(#[0-9]+)\b`)
+
+// issueKeyPattern matches an issue reference in a footer value, e.g. "#45" or "45" in "Closes: 45".
+var issueKeyPattern = regexp.MustCompile(`^#?[0-9]+$`)
+
+// referenceFooterTokens are the footer tokens whose values are issue references, e.g. "Closes #45".
+var referenceFooterTokens = []string{"Close", "Closes", "Closed", "Fix", "Fixes", "Fixed", "Resolve", "Resolves", "Resolved", "Ref", "Refs", "References"}
+
+// noreplyEmailPattern matches the no-reply commit email addresses of GitHub and GitLab users
+// and captures the user name, e.g. "123+octocat@users.noreply.github.com".
+var noreplyEmailPattern = regexp.MustCompile(`^(?:[0-9]+[+-])?([^@]+)@users\.noreply\.(?:github\.com|gitlab\.com)$`)
+
+// changelogLinker turns commits and issue references in the release notes into links.
+// The zero value leaves everything as it is.
+type changelogLinker struct {
+	repoURL     string // Web URL of the repository, empty if unknown
+	commitLinks bool
+	authors     bool
+	references  []referenceLinker
+}
+
+// referenceLinker links the references matched by a pattern.
+type referenceLinker struct {
+	pattern *regexp.Regexp
+	group   int    // Capture group of the reference in pattern
+	url     string // URL of a reference, {{.ID}} is replaced by the reference
+}
+
+// newChangelogLinker returns the linker for the changelog settings and the repository URL.
+func newChangelogLinker(cfg *Config, repoURL string) *changelogLinker {
+	l := &changelogLinker{repoURL: repoURL, commitLinks: cfg.Changelog.CommitLinks, authors: cfg.Changelog.Authors}
+
+	issueURL := cfg.Changelog.IssueURL
+	if issueURL == "" {
+		issueURL = defaultIssueURL(repoURL)
+	}
+	if issueURL != "" {
+		l.references = append(l.references, referenceLinker{pattern: issueReferencePattern, group: 2, url: issueURL})
+	}
+	for _, ref := range cfg.Changelog.References {
+		if pattern, err := regexp.Compile(ref.Pattern); err == nil { // Validated when the configuration is loaded
+			l.references = append(l.references, referenceLinker{pattern: pattern, url: ref.URL})
+		}
+	}
+	return l
+}
+
+// funcs returns the template functions of the linker.
+func (l *changelogLinker) funcs() template.FuncMap {
+	return template.FuncMap{
+		"linkReferences": l.linkReferences,
+		"commitLink":     l.commitLink,
+		"references":     l.footerReferences,
+		"author":         l.author,
+	}
+}
+
+// linkReferences replaces the issue references in text by Markdown links.
+func (l *changelogLinker) linkReferences(text string) string {
+	type match struct {
+		start, end int
+		url        string
+	}
+	var matches []match
+	for _, ref := range l.references {
+		for _, m := range ref.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[2*ref.group], m[2*ref.group+1]
+			if start < 0 {
+				continue
+			}
+			id := strings.TrimPrefix(text[start:end], "#")
+			matches = append(matches, match{start, end, strings.ReplaceAll(ref.url, "{{.ID}}", id)})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.start < last {
+			continue // Overlaps a reference matched by an earlier pattern
+		}
+		fmt.Fprintf(&sb, "%s[%s](%s)", text[last:m.start], text[m.start:m.end], m.url)
+		last = m.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// commitLink returns the short SHA of the commit, linked to the commit if the repository URL is
+// known, or an empty string if commit links are disabled.
+func (l *changelogLinker) commitLink(commit Commit) string {
+	if !l.commitLinks || commit.SHA == "" {
+		return ""
+	}
+	if l.repoURL == "" {
+		return shortSHA(commit.SHA)
+	}
+	return fmt.Sprintf("[%s](%s)", shortSHA(commit.SHA), commitURL(l.repoURL, commit.SHA))
+}
+
+// footerReferences returns the issue references in the footers of the commit, e.g.
+// ", closes [#45](...), refs [JIRA-12](...)", or an empty string if there are none.
+// Words of the footer values that are not references, e.g. in "Refs: the discussion in chat",
+// are left out.
+func (l *changelogLinker) footerReferences(commit Commit) string {
+	var sb strings.Builder
+	for _, footer := range commit.Footers {
+		if !isReferenceFooter(footer.Token) {
+			continue
+		}
+		var refs []string
+		for _, ref := range strings.FieldsFunc(footer.Value, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+			ref = strings.TrimRight(ref, ".;:")
+			if issueKeyPattern.MatchString(ref) {
+				ref = "#" + strings.TrimPrefix(ref, "#") // "Closes #45" is parsed as token "Closes" with the value "45"
+			} else if !l.isReference(ref) {
+				continue
+			}
+			refs = append(refs, l.linkReferences(ref))
+		}
+		if len(refs) > 0 {
+			fmt.Fprintf(&sb, ", %s %s", strings.ToLower(footer.Token), strings.Join(refs, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// isReference reports whether the whole word is a reference matched by one of the patterns.
+func (l *changelogLinker) isReference(word string) bool {
+	for _, ref := range l.references {
+		if m := ref.pattern.FindStringSubmatchIndex(word); m != nil && m[2*ref.group] == 0 && m[2*ref.group+1] == len(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// author returns the handle of the commit author if it can be derived from a no-reply email
+// address (e.g. "@octocat"), the author name otherwise, or an empty string if authors are disabled.
+func (l *changelogLinker) author(commit Commit) string {
+	if !l.authors {
+		return ""
+	}
+	if m := noreplyEmailPattern.FindStringSubmatch(commit.Email); m != nil {
+		return "@" + m[1]
+	}
+	return commit.Author
+}
+
+// isReferenceFooter reports whether the footer token introduces issue references.
+func isReferenceFooter(token string) bool {
+	for _, t := range referenceFooterTokens {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReferences checks the issue tracker settings of the changelog.
+func validateReferences(refs []ReferenceConfig) []configError {
+	var errs []configError
+	for i, ref := range refs {
+		key := fmt.Sprintf("changelog.references.%d", i)
+		if ref.Pattern == "" {
+			errs = append(errs, configError{Key: key + ".pattern", Message: "must not be empty"})
+		} else if _, err := regexp.Compile(ref.Pattern); err != nil {
+			errs = append(errs, configError{Key: key + ".pattern", Message: fmt.Sprintf("invalid regular expression: %v", err)})
+		}
+		if !strings.Contains(ref.URL, "{{.ID}}") {
+			errs = append(errs, configError{Key: key + ".url", Message: "must contain the {{.ID}} placeholder"})
+		}
+	}
+	return errs
+}
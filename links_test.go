@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestFooterReferences(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Changelog.References = []ReferenceConfig{{Pattern: "[A-Z][A-Z0-9]+-[0-9]+", URL: "https://jira.example.com/browse/{{.ID}}"}}
+	linker := newChangelogLinker(cfg, "https://github.com/o/r")
+
+	tests := []struct {
+		name    string
+		footers []Footer
+		want    string
+	}{
+		{name: "issue number", footers: []Footer{{Token: "Closes", Value: "45"}}, want: ", closes [#45](https://github.com/o/r/issues/45)"},
+		{name: "issue reference", footers: []Footer{{Token: "Fixes", Value: "#7, #8"}}, want: ", fixes [#7](https://github.com/o/r/issues/7), [#8](https://github.com/o/r/issues/8)"},
+		{name: "configured key", footers: []Footer{{Token: "Refs", Value: "JIRA-12"}}, want: ", refs [JIRA-12](https://jira.example.com/browse/JIRA-12)"},
+		{name: "prose", footers: []Footer{{Token: "Refs", Value: "the discussion in chat"}}, want: ""},
+		{name: "prose around references", footers: []Footer{{Token: "Refs", Value: "see #12 and JIRA-3."}}, want: ", refs [#12](https://github.com/o/r/issues/12), [JIRA-3](https://jira.example.com/browse/JIRA-3)"},
+		{name: "partial key", footers: []Footer{{Token: "Refs", Value: "xJIRA-12y"}}, want: ""},
+		{name: "other footer", footers: []Footer{{Token: "Reviewed-by", Value: "Z"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linker.footerReferences(Commit{Footers: tt.footers}); got != tt.want {
+				t.Errorf("footerReferences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,9 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -25,9 +25,27 @@ var requiredGitConfigs = []string{
 	"user.email",
 }
 
+// commitTypes are the commit types accepted in commit headers, see setCommitTypes.
+var commitTypes = defaultConfig().Types
+
 // commitPattern follows the Conventional Commits specification
 // See: https://www.conventionalcommits.org/
-var commitPattern = regexp.MustCompile(`^(?P<type>feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(?:\((?P<scope>[^()\r\n]*)\)|\()?(?P<breaking>!)?: (?P<subject>.*)$`)
+var commitPattern = buildCommitPattern(commitTypes)
+
+// buildCommitPattern builds the commit header pattern accepting the given commit types.
+func buildCommitPattern(types []string) *regexp.Regexp {
+	quoted := make([]string, len(types))
+	for i, t := range types {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`^(?P<type>` + strings.Join(quoted, "|") + `)(?:\((?P<scope>[^()\r\n]*)\)|\()?(?P<breaking>!)?: (?P<subject>.*)$`)
+}
+
+// setCommitTypes replaces the accepted commit types, e.g. with the ones from the config file.
+func setCommitTypes(types []string) {
+	commitTypes = types
+	commitPattern = buildCommitPattern(types)
+}
 
 // validateCommitMessage checks if a commit message follows the Conventional Commits spec
 func validateCommitMessage(message string) (bool, string) {
@@ -36,17 +54,17 @@ func validateCommitMessage(message string) (bool, string) {
 		return true, ""
 	}
 
-	// Check if message matches the pattern
-	if !commitPattern.MatchString(message) {
+	// Only the header has to match the pattern; body and footers are free-form
+	if !parseCommit(message).Conventional {
 		errMsg := fmt.Sprintf(`
 Invalid commit message format: "%s"
 
 Please follow the Conventional Commits specification:
 <type>[optional scope]: <description>
 
-Available types: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert
+Available types: %s
 
-Example: feat(auth): add login functionality`, strings.TrimSpace(message))
+Example: feat(auth): add login functionality`, strings.TrimSpace(message), strings.Join(commitTypes, ", "))
 		return false, errMsg
 	}
 	return true, ""
@@ -106,20 +124,25 @@ func isGitRepository() bool {
 }
 
 func main() {
-	// Define flags
+	// Define flags. Settings that can also come from the config file or the environment
+	// are applied to the configuration in loadConfig, see flagConfigKeys.
 	showAppVersion := flag.Bool("version", false, "Display the application's version.")
 	gitDir := flag.String("git-address", ".", "Path to git repository (default: current directory)")
-	branch := flag.String("branch", "", "Branch name (default: current branch)")
-	preRelease := flag.Bool("preRelease", false, "Enable pre-release versioning based on branch name")
-	ciMode := flag.Bool("ci", false, "Run in CI mode (auto-detect branch, auto-push tags)")
-	pushBranch := flag.Bool("push-branch", false, "Push the branch to remote if it doesn't exist or is behind")
+	configPath := flag.String("config", "", "Path to the config file (default: .semvergo.yaml, .semvergo.yml or .semvergo.toml at the repository root)")
+	flag.String("branch", "", "Branch name (default: current branch)")
+	flag.Bool("preRelease", false, "Enable pre-release versioning based on branch name")
+	flag.Bool("ci", false, "Run in CI mode (auto-detect branch, auto-push tags)")
+	flag.Bool("push-branch", false, "Push the branch to remote if it doesn't exist or is behind")
 	setVersionFlag := flag.String("set-version", "", "Specify the exact version to be released (e.g., 1.2.3) to override automatic versioning.")
-	skipChecks := flag.Bool("skip-checks", false, "Skip git configuration and status checks (use with caution)")
+	flag.Bool("skip-checks", false, "Skip git configuration and status checks (use with caution)")
 	nextVersionOnly := flag.Bool("next-version-only", false, "Only display the next version, do not create a tag")
-	tagFormat := flag.String("tag-format", "v{{.Major}}.{{.Minor}}.{{.Patch}}{{.Prerelease}}", "Custom format for the git tag. Placeholders: {{.Major}}, {{.Minor}}, {{.Patch}}, {{.Prerelease}} (includes leading hyphen if present, e.g., '-beta.1'). Example: 'v{{.Major}}.{{.Minor}}.{{.Patch}}{{.Prerelease}}' or 'release-{{.Major}}.{{.Minor}}.{{.Patch}}'")
-	debugMode := flag.Bool("debug", false, "Enable debug output for verbose logging")
-	outputChangelogEnabled := flag.Bool("output-changelog", false, "Enable generation of CHANGELOG.md file. Defaults to false.")
-	dryRun := flag.Bool("dry-run", false, "Perform a dry run, showing what would happen without making changes.")
+	packageName := flag.String("package", "", "Release only the named package of a monorepo (see 'packages' in the config file)")
+	flag.String("tag-format", "", "Custom format for the git tag (default: tag_prefix followed by the version, e.g. 'v1.2.3'). Placeholders: {{.Major}}, {{.Minor}}, {{.Patch}}, {{.Prerelease}} (includes leading hyphen if present, e.g., '-beta.1'). Example: 'v{{.Major}}.{{.Minor}}.{{.Patch}}{{.Prerelease}}' or 'release-{{.Major}}.{{.Minor}}.{{.Patch}}'")
+	flag.Bool("first-parent", false, "Only consider version tags on the first-parent history of HEAD, ignoring tags of merged branches")
+	flag.Bool("debug", false, "Enable debug output for verbose logging")
+	flag.Bool("output-changelog", false, "Enable generation of CHANGELOG.md file. Defaults to false.")
+	flag.Bool("dry-run", false, "Perform a dry run, showing what would happen without making changes.")
+	outputFormat := flag.String("output", "text", "Output format: text, or json to write a JSON document of the result to stdout and all other output to stderr")
 
 	// Parse flags
 	flag.Parse()
@@ -130,617 +153,433 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *dryRun {
-		fmt.Println("Dry run mode enabled: No actual changes will be made to the Git repository or files.")
+	switch *outputFormat {
+	case "text":
+	case "json":
+		report.enableJSON()
+	default:
+		fmt.Printf("Error: invalid output format %q, must be one of text, json\n", *outputFormat)
+		os.Exit(1)
 	}
-
-	if *debugMode {
-		fmt.Printf("DEBUG: Raw tagFormat flag value: '%s'\n", *tagFormat)
+	report.Command = "release"
+	if args := flag.Args(); len(args) > 0 {
+		report.Command = args[0]
 	}
 
 	absGitDir, err := filepath.Abs(*gitDir)
 	if err != nil {
 		fmt.Printf("Error getting absolute path: %v\n", err)
-		os.Exit(1)
+		exit(nil, err)
 	}
 
 	if err := os.Chdir(absGitDir); err != nil {
 		fmt.Printf("Error changing to git directory: %v\n", err)
-		os.Exit(1)
+		exit(nil, withCode(errCodeNotRepository, err))
 	}
 
 	if !isGitRepository() {
 		fmt.Printf("Error: '%s' is not a Git repository.\n", absGitDir)
-		os.Exit(1)
+		exit(nil, withCode(errCodeNotRepository, fmt.Errorf("'%s' is not a Git repository", absGitDir)))
 	}
 
-	if !*skipChecks {
-		if err := validateGitConfig(); err != nil {
-			fmt.Printf("Git configuration error: %v\n", err)
-			os.Exit(1)
-		}
-
-		if err := checkGitStatus(); err != nil {
-			fmt.Printf("Git status check failed: %v\n", err)
-			os.Exit(1)
+	cfg, err := loadConfig(*configPath, flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Configuration error: %v\n", err)
+		exit(nil, withCode(errCodeConfig, err))
+	}
+
+	// Commands other than the default release flow
+	if args := flag.Args(); len(args) > 0 {
+		var cmdErr error
+		switch args[0] {
+		case "config":
+			cmdErr = runConfigCommand(cfg, args[1:])
+		case "release-all":
+			cmdErr = runReleaseAll(cfg, releaseOptions{SetVersion: *setVersionFlag, NextVersionOnly: *nextVersionOnly})
+		case "changelog":
+			target, err := selectTarget(cfg, *packageName)
+			if err != nil {
+				cmdErr = err
+				break
+			}
+			cmdErr = runChangelogCommand(cfg, target, args[1:])
+		case "notes":
+			target, err := selectTarget(cfg, *packageName)
+			if err != nil {
+				cmdErr = err
+				break
+			}
+			cmdErr = runNotesCommand(cfg, target, args[1:])
+		case "promote":
+			target, err := selectTarget(cfg, *packageName)
+			if err != nil {
+				cmdErr = err
+				break
+			}
+			cmdErr = runPromote(cfg, target, args[1:], releaseOptions{SetVersion: *setVersionFlag, NextVersionOnly: *nextVersionOnly})
+		default:
+			cmdErr = withCode(errCodeUsage, fmt.Errorf("unknown command %q", args[0]))
 		}
-	} else if *ciMode {
-		fmt.Println("Skipping Git checks in CI mode.")
-	}
-
-	if *branch == "" {
-		out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
-		if err != nil {
-			fmt.Printf("Error getting current branch: %v\n", err)
-			os.Exit(1)
+		if cmdErr != nil {
+			fmt.Printf("Error: %v\n", cmdErr)
 		}
-		*branch = strings.TrimSpace(string(out))
+		exit(cfg, cmdErr)
 	}
 
-	if !*preRelease && !isDefaultBranch(*branch) {
-		*preRelease = true
-		fmt.Printf("Auto-enabled pre-release for non-default branch: %s\n", *branch)
-	} else {
-		fmt.Printf("Pre-release mode: %v\n", *preRelease)
-	}
-
-	if *ciMode {
-		fmt.Printf("CI Mode: Branch: %s, Pre-release: %v\n", *branch, *preRelease)
-	}
-
-	// Get current version to determine the commit range for analysis
-	currentVersionForCommits, err := getCurrentVersion()
+	target, err := selectTarget(cfg, *packageName)
 	if err != nil {
-		fmt.Printf("Error getting current version for commit analysis: %v\n", err)
-		os.Exit(1)
-	}
-	var fromRef string
-	if currentVersionForCommits.Major() == 0 && currentVersionForCommits.Minor() == 0 && currentVersionForCommits.Patch() == 0 && currentVersionForCommits.Prerelease() == "" {
-		fromRef = "" // If starting from 0.0.0, get all commits
-	} else {
-		fromRef = "v" + currentVersionForCommits.String()
+		fmt.Printf("Error: %v\n", err)
+		exit(cfg, withCode(errCodeConfig, err))
 	}
 
-	commitMessages, err := getCommitMessagesBetweenRefs(fromRef, "HEAD")
+	ok, err := prepareRelease(cfg)
 	if err != nil {
-		fmt.Printf("Error getting commit messages for analysis: %v\n", err)
-		os.Exit(1)
+		fmt.Println(err)
+		exit(cfg, err)
 	}
-
-	if *debugMode {
-		fmt.Printf("DEBUG: Commit messages for bump type analysis (from %s to HEAD):\n", fromRef)
-		for i, msg := range commitMessages {
-			fmt.Printf("  - %d: '%s'\n", i, msg)
-		}
+	if !ok {
+		exit(cfg, nil)
 	}
 
-	// Validate the latest commit message for format, but determine bump type from all relevant commits
-	latestCommitMsgBytes, err := exec.Command("git", "log", "-1", "--pretty=%B").Output()
+	tagName, err := release(cfg, target, releaseOptions{SetVersion: *setVersionFlag, NextVersionOnly: *nextVersionOnly})
 	if err != nil {
-		fmt.Printf("Error getting latest commit message for validation: %v\n", err)
-		os.Exit(1)
+		fmt.Println(err)
+		exit(cfg, err)
 	}
-	latestCommitMsg := strings.TrimSpace(string(latestCommitMsgBytes))
 
-	if ok, errMsg := validateCommitMessage(latestCommitMsg); !ok {
-		fmt.Printf("Invalid latest commit message: %s\n", errMsg)
-		os.Exit(1)
+	// If next-version-only flag is set, just print the version
+	if *nextVersionOnly && tagName != "" {
+		fmt.Println(tagName)
 	}
+	exit(cfg, nil)
+}
 
-	bumpType, err := determineBumpType(commitMessages) // Pass all relevant commit messages
+// exit writes the report of the run in JSON mode and exits, with status 1 if err is set.
+// cfg is nil if the configuration is not loaded yet.
+func exit(cfg *Config, err error) {
+	report.finish(cfg, err)
 	if err != nil {
-		fmt.Printf("Error determining version bump type: %v\n", err)
 		os.Exit(1)
 	}
+	os.Exit(0)
+}
 
-	if bumpType == "none" {
-		fmt.Println("No version bump needed based on commit history.")
-		os.Exit(0)
-	}
-	
-	fmt.Printf("Valid commit message: %s\n", latestCommitMsg) // Still show the latest commit message
-	fmt.Printf("Based on commit history, will perform %s version bump.\n", bumpType)
-
-	var newVersion string
-	var currentVersion *semver.Version // Store current version for changelog generation
-
-	// Re-fetch current version if needed, or use currentVersionForCommits
-	currentVersion = currentVersionForCommits // Use the version fetched earlier for consistency
-
-	if *setVersionFlag != "" { // Use the new setVersionFlag
-		versionStr := *setVersionFlag
-		if !strings.HasPrefix(versionStr, "v") {
-			versionStr = "v" + versionStr
-		}
-
-		if tagExists(versionStr) {
-			fmt.Printf("Error: Version %s already exists as a tag.\n", versionStr)
-			os.Exit(1)
-		}
-
-		_, verErr := semver.NewVersion(strings.TrimPrefix(versionStr, "v"))
-		if verErr != nil {
-			fmt.Printf("Error: Invalid version format: %v\n", verErr)
-			os.Exit(1)
-		}
+// commitFieldSeparator separates the fields of a single commit in the git log output.
+// Commits themselves are separated by NUL bytes (git log -z).
+const commitFieldSeparator = "\x1f"
 
-		newVersion = strings.TrimPrefix(versionStr, "v")
+// getCommitsBetweenRefs gets the commits between two Git references (tags, branches, SHAs).
+// If paths are given, only commits touching files matching one of the path globs are returned;
+// globs starting with '!' exclude matching files.
+func getCommitsBetweenRefs(fromRef, toRef string, paths ...string) ([]Commit, error) {
+	var commitRange string
+	if fromRef == "" {
+		// If fromRef is empty, get all commits up to toRef
+		commitRange = toRef
 	} else {
-		newVersion, err = calculateNewVersion(currentVersion, bumpType, *branch, *preRelease)
-		if err != nil {
-			fmt.Printf("Error calculating new version: %v\n", err)
-			os.Exit(1)
-		}
-	}
-
-	if *debugMode {
-		fmt.Printf("DEBUG: Calculated newVersion string: '%s'\n", newVersion)
+		commitRange = fmt.Sprintf("%s..%s", fromRef, toRef)
 	}
 
-	var finalTagName string
-	if *tagFormat != "" {
-		parsedNewVer, err := semver.NewVersion(newVersion)
+	// Excludes alone, e.g. the nested Go modules of the root module, only leave out the commits
+	// changing nothing but excluded files. As a pathspec, they would leave out the commits
+	// changing no files at all as well.
+	if len(paths) > 0 && onlyExcludes(paths) {
+		commits, err := getCommitsBetweenRefs(fromRef, toRef)
 		if err != nil {
-			fmt.Printf("Error parsing calculated new version '%s' for formatting: %v\n", newVersion, err)
-			os.Exit(1)
+			return nil, err
 		}
-
-		if *debugMode {
-			fmt.Printf("DEBUG: Parsed New Version: %s\n", parsedNewVer.String())
-			fmt.Printf("DEBUG: Major: %d, Minor: %d, Patch: %d, Prerelease: '%s'\n",
-				parsedNewVer.Major(), parsedNewVer.Minor(), parsedNewVer.Patch(), parsedNewVer.Prerelease())
+		excluded := make([]string, len(paths))
+		for i, p := range paths {
+			excluded[i] = strings.TrimPrefix(p, "!")
 		}
-
-		formattedTag := *tagFormat
-		if *debugMode {
-			fmt.Printf("DEBUG: Initial formattedTag for replacements: '%s'\n", formattedTag)
-		}
-
-		formattedTag = strings.ReplaceAll(formattedTag, "{{.Major}}", strconv.FormatUint(parsedNewVer.Major(), 10))
-		if *debugMode {
-			fmt.Printf("DEBUG: formattedTag after {{.Major}}: '%s'\n", formattedTag)
-		}
-
-		formattedTag = strings.ReplaceAll(formattedTag, "{{.Minor}}", strconv.FormatUint(parsedNewVer.Minor(), 10))
-		if *debugMode {
-			fmt.Printf("DEBUG: formattedTag after {{.Minor}}: '%s'\n", formattedTag)
+		touchingExcluded, err := commitSHAs(commitRange, excluded)
+		if err != nil {
+			return nil, err
 		}
-
-		formattedTag = strings.ReplaceAll(formattedTag, "{{.Patch}}", strconv.FormatUint(parsedNewVer.Patch(), 10))
-		if *debugMode {
-			fmt.Printf("DEBUG: formattedTag after {{.Patch}}: '%s'\n", formattedTag)
+		touchingOthers, err := commitSHAs(commitRange, paths)
+		if err != nil {
+			return nil, err
 		}
-
-		prereleasePart := parsedNewVer.Prerelease()
-		if prereleasePart != "" {
-			formattedTag = strings.ReplaceAll(formattedTag, "{{.Prerelease}}", "-"+prereleasePart)
-			if *debugMode {
-				fmt.Printf("DEBUG: formattedTag after {{.Prerelease}} (with value): '%s'\n", formattedTag)
-			}
-		} else {
-			formattedTag = strings.ReplaceAll(formattedTag, "{{.Prerelease}}", "")
-			if *debugMode {
-				fmt.Printf("DEBUG: formattedTag after {{.Prerelease}} (empty value): '%s'\n", formattedTag)
+		var kept []Commit
+		for _, commit := range commits {
+			if !touchingExcluded[commit.SHA] || touchingOthers[commit.SHA] {
+				kept = append(kept, commit)
 			}
 		}
-		finalTagName = formattedTag
-	} else {
-		finalTagName = "v" + newVersion
+		return kept, nil
 	}
 
-	// If next-version-only flag is set, just print the version and exit
-	if *nextVersionOnly {
-		fmt.Println(finalTagName)
-		os.Exit(0)
+	// SHA, author name and email, strict ISO 8601 author date and raw body, separated by the unit separator
+	args := []string{"log", "-z", "--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%B", commitRange}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, pathspecs(paths)...)
+	}
+	logCmd := exec.Command("git", args...)
+	out, err := logCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting commits for range %s: %v\nOutput: %s", commitRange, err, gitErrorOutput(err))
 	}
 
-	changelogPath := "CHANGELOG.md" // The fixed path for the changelog
-	changelogGenerated := false     // Flag to track if changelog was actually generated
-
-	// Generate Release Notes if --output-changelog is enabled AND not in pre-release mode
-	// Release notes are typically generated for final releases, not pre-releases.
-	if *outputChangelogEnabled && !*preRelease { // Check the boolean flag
-		// Use the previous release tag as the 'from' tag for changelog generation
-		// and "HEAD" as the 'to' reference, as the new tag might not exist yet.
-		oldTag := "v" + currentVersion.String() // Assuming currentVersion is the last *release* tag
-		if currentVersion.Major() == 0 && currentVersion.Minor() == 0 && currentVersion.Patch() == 0 && currentVersion.Prerelease() == "" {
-			// If starting from 0.0.0, use an empty string for the 'from' tag to get all commits
-			oldTag = ""
+	var commits []Commit
+	for _, record := range strings.Split(string(out), "\x00") {
+		if strings.TrimSpace(record) == "" {
+			continue
 		}
-		
-		fmt.Printf("Generating release notes from %s to %s (HEAD)...\n", oldTag, finalTagName) // Log finalTagName for clarity
-		if *dryRun {
-			fmt.Printf("[DRY-RUN] Would generate release notes to: %s\n", changelogPath) // Hardcode for dry-run message
-		} else {
-			if err := generateReleaseNotes(oldTag, finalTagName, changelogPath, *debugMode); err != nil { // Hardcode for actual write
-				fmt.Printf("Error generating release notes: %v\n", err)
-				// Don't exit, allow tag creation to proceed even if notes fail
-			} else {
-				fmt.Printf("Release notes generated and saved to %s\n", changelogPath)
-				changelogGenerated = true // Set flag if successful
-			}
+		fields := strings.SplitN(record, commitFieldSeparator, 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected git log record in range %s: %q", commitRange, record)
 		}
-	}
 
-	// Add and Commit Changelog if it was generated and not in dry-run mode
-	if changelogGenerated && !*dryRun {
-		fmt.Printf("Committing %s...\n", changelogPath)
-		if err := addAndCommitChangelog(changelogPath, finalTagName); err != nil {
-			fmt.Printf("Error committing changelog: %v\n", err)
-			os.Exit(1) // This is a critical step, so exit on failure
+		commit := parseCommit(fields[4])
+		commit.SHA = strings.TrimSpace(fields[0])
+		commit.Author = fields[1]
+		commit.Email = fields[2]
+		commit.Date, err = time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date of commit %s: %v", commit.SHA, err)
 		}
-		fmt.Printf("Changelog %s committed.\n", changelogPath)
-	} else if changelogGenerated && *dryRun {
-		fmt.Printf("[DRY-RUN] Would commit %s with message 'chore(release): update changelog for %s [skip-ci]'.\n", changelogPath, finalTagName)
+		commits = append(commits, commit)
 	}
+	return commits, nil
+}
 
-	// Create git tag
-	fmt.Printf("Creating tag: %s\n", finalTagName)
-	if *dryRun {
-		fmt.Printf("[DRY-RUN] Would create tag: %s\n", finalTagName)
-	} else {
-		if err := createGitTag(finalTagName); err != nil {
-			fmt.Printf("Error creating git tag: %v\n", err)
-			os.Exit(1)
-		}
+// commitSHAs returns the commits of the range changing files matched by the path globs.
+func commitSHAs(commitRange string, paths []string) (map[string]bool, error) {
+	args := append([]string{"log", "--format=%H", "--full-history", commitRange, "--"}, pathspecs(paths)...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting commits for range %s: %v\nOutput: %s", commitRange, err, gitErrorOutput(err))
 	}
-
-	// Push tag if in CI mode or push-branch is enabled
-	if *ciMode || *pushBranch {
-		if *dryRun {
-			fmt.Printf("[DRY-RUN] Would push tag: %s\n", finalTagName)
-			if *pushBranch {
-				fmt.Println("[DRY-RUN] Would also push the current branch.")
-			}
-		} else {
-			if err := pushTag(finalTagName); err != nil {
-				fmt.Printf("Error pushing tag: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Successfully created and pushed version: %s\n", finalTagName)
-
-			// Push the branch if push-branch is enabled
-			if err := pushCurrentBranch(); err != nil {
-				fmt.Printf("Warning: Could not push branch: %v\n", err)
-			} else {
-				fmt.Printf("Successfully pushed branch to remote.\n")
-			}
-		}
-	} else {
-		fmt.Printf("New version created: %s\n", finalTagName)
-		fmt.Printf("Run 'git push origin %s' to push the tag to remote.\n", finalTagName)
+	shas := map[string]bool{}
+	for _, sha := range strings.Fields(string(out)) {
+		shas[sha] = true
 	}
+	return shas, nil
 }
 
-// getCommitMessagesBetweenRefs gets commit messages between two Git references (tags, branches, SHAs)
-func getCommitMessagesBetweenRefs(fromRef, toRef string) ([]string, error) {
-	var commitRange string
-	if fromRef == "" {
-		// If fromRef is empty, get all commits up to toRef
-		commitRange = toRef
-	} else {
-		commitRange = fmt.Sprintf("%s..%s", fromRef, toRef)
+// onlyExcludes reports whether all path globs are excludes, e.g. "!tools/**".
+func onlyExcludes(paths []string) bool {
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "!") {
+			return false
+		}
 	}
+	return true
+}
 
-	logCmd := exec.Command("git", "log", "--format=%B", commitRange)
-	out, err := logCmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("error getting commit messages for range %s: %v\nOutput: %s", commitRange, err, string(out))
+// pathspecs converts path globs to git pathspecs, see gitglossary(7).
+func pathspecs(paths []string) []string {
+	specs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if strings.HasPrefix(p, "!") {
+			specs = append(specs, ":(glob,exclude)"+strings.TrimPrefix(p, "!"))
+		} else {
+			specs = append(specs, ":(glob)"+p)
+		}
 	}
+	return specs
+}
 
-	messages := strings.Split(string(out), "\n\n")
-	var commitMsgs []string
-	for _, msg := range messages {
-		msg = strings.TrimSpace(msg)
-		if msg != "" {
-			commitMsgs = append(commitMsgs, msg)
-		}
+// gitErrorOutput returns the stderr captured by exec for a failed git command, if any.
+func gitErrorOutput(err error) string {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return strings.TrimSpace(string(exitErr.Stderr))
 	}
-	return commitMsgs, nil
+	return ""
 }
 
-// determineBumpType analyzes a list of commit messages to determine the version bump type
-func determineBumpType(commitMsgs []string) (string, error) {
+// determineBumpType analyzes a list of parsed commits to determine the version bump type
+func determineBumpType(commits []Commit, rules []BumpRule) (string, error) {
 	bumpType := "none" // Default to 'none' if no version-bumping commits are found
 
-	for _, msg := range commitMsgs {
-		msg = strings.TrimSpace(msg)
-		if msg == "" || strings.HasPrefix(msg, "Merge ") {
+	for _, commit := range commits {
+		if commit.Merge || !commit.Conventional {
+			// Commits that don't follow conventional commits are skipped for bump type determination,
+			// assuming validateCommitMessage handles format validation.
 			continue
 		}
 
-		matches := commitPattern.FindStringSubmatch(msg)
-		if len(matches) == 0 {
-			// If it doesn't match conventional commits, it's an invalid format for version bumping.
-			// We skip it for bump type determination, assuming validateCommitMessage handles format validation.
-			continue
-		}
-
-		commitType := matches[commitPattern.SubexpIndex("type")]
-		isBreaking := matches[commitPattern.SubexpIndex("breaking")] != "" || strings.Contains(msg, "BREAKING CHANGE:")
-
-		if isBreaking {
+		if commit.Breaking {
 			return "major", nil // Breaking change takes highest precedence, immediately return major
 		}
 
-		if commitType == "feat" {
-			if bumpType != "major" { // Only set to minor if not already major
-				bumpType = "minor"
-			}
-		} else if commitType == "fix" {
-			if bumpType != "major" && bumpType != "minor" { // Only set to patch if not already major or minor
-				bumpType = "patch"
-			}
+		if commitBump := commitBumpType(commit, rules); bumpLevels[commitBump] > bumpLevels[bumpType] {
+			bumpType = commitBump
 		}
-		// For other commit types (docs, style, refactor, perf, test, build, ci, chore, revert),
-		// if no 'major', 'minor', or 'patch' has been determined yet, 'bumpType' remains 'none'.
 	}
 
 	return bumpType, nil
 }
 
-// generateReleaseNotes creates Markdown formatted release notes
-func generateReleaseNotes(oldTag, newTagForHeader, outputPath string, debugMode bool) error {
-	// Use "HEAD" as the 'toRef' for git log, as newTagForHeader might not exist yet as a tag
-	commitMsgs, err := getCommitMessagesBetweenRefs(oldTag, "HEAD")
-	if err != nil {
-		return fmt.Errorf("failed to get commit messages for release notes: %v", err)
-	}
-
-	if debugMode {
-		fmt.Printf("DEBUG: Commits for release notes (%s..HEAD):\n", oldTag)
-		for i, msg := range commitMsgs {
-			fmt.Printf("  %d: '%s'\n", i, msg)
-		}
-	}
-
-	// Categorize commits
-	breakingChanges := []string{}
-	features := []string{}
-	bugFixes := []string{}
-	otherChanges := []string{}
-
-	for _, msg := range commitMsgs {
-		if shouldSkipCI(msg) || strings.HasPrefix(msg, "Merge ") {
-			continue // Skip commits that should not be in release notes
-		}
-
-		matches := commitPattern.FindStringSubmatch(msg)
-		if len(matches) == 0 {
-			// If it doesn't match conventional commits, add to other changes
-			otherChanges = append(otherChanges, strings.Split(msg, "\n")[0]) // Just take the subject line
-			continue
-		}
-
-		commitType := matches[commitPattern.SubexpIndex("type")]
-		commitSubject := matches[commitPattern.SubexpIndex("subject")]
-		isBreaking := matches[commitPattern.SubexpIndex("breaking")] != "" || strings.Contains(msg, "BREAKING CHANGE:")
-
-		if isBreaking {
-			// Extract full breaking change message if present in body
-			breakingMsg := fmt.Sprintf("- **BREAKING CHANGE:** %s", commitSubject)
-			if strings.Contains(msg, "BREAKING CHANGE:") {
-				parts := strings.SplitN(msg, "BREAKING CHANGE:", 2)
-				if len(parts) > 1 {
-					breakingMsg += "\n  " + strings.TrimSpace(parts[1])
-				}
-			}
-			breakingChanges = append(breakingChanges, breakingMsg)
-		}
-
-		// Only add to categories if not a breaking change (to avoid duplication)
-		if !isBreaking {
-			switch commitType {
-			case "feat":
-				features = append(features, fmt.Sprintf("- **feat:** %s", commitSubject))
-			case "fix":
-				bugFixes = append(bugFixes, fmt.Sprintf("- **fix:** %s", commitSubject))
-			default:
-				// Include other types that might be relevant for a changelog, but not major/minor/patch bumps
-				if commitType != "docs" && commitType != "style" && commitType != "test" && commitType != "chore" {
-					otherChanges = append(otherChanges, fmt.Sprintf("- **%s:** %s", commitType, commitSubject))
-				}
-			}
-		}
-	}
+// initialDevelopmentBump lowers a bump by one level for versions 0.y.z, where anything may change:
+// breaking changes bump the minor version and features the patch version.
+// See https://semver.org/#spec-item-4
+func initialDevelopmentBump(bumpType string) string {
+	switch bumpType {
+	case "major":
+		return "minor"
+	case "minor":
+		return "patch"
+	}
+	return bumpType
+}
 
-	// Build Markdown content
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("## %s (%s)\n\n", newTagForHeader, time.Now().Format("2006-01-02")))
+// releaseAsFooter is the footer of a commit requesting the version of the next release,
+// e.g. "Release-As: 1.0.0" to end the initial development.
+const releaseAsFooter = "Release-As"
 
-	if len(breakingChanges) > 0 {
-		sb.WriteString("### BREAKING CHANGES\n\n")
-		for _, change := range breakingChanges {
-			sb.WriteString(change + "\n")
+// requestedVersion returns the version requested by the most recent commit with a Release-As footer.
+func requestedVersion(commits []Commit) (string, bool) {
+	for _, commit := range commits { // Newest first
+		if value, ok := commit.FooterValue(releaseAsFooter); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), true
 		}
-		sb.WriteString("\n")
 	}
+	return "", false
+}
 
-	if len(features) > 0 {
-		sb.WriteString("### Features\n\n")
-		for _, feature := range features {
-			sb.WriteString(feature + "\n")
+// commitBumpType returns the bump of the first rule matching the commit, or "none".
+func commitBumpType(commit Commit, rules []BumpRule) string {
+	for _, rule := range rules {
+		if rule.matches(commit) {
+			return rule.Bump
 		}
-		sb.WriteString("\n")
 	}
+	return "none"
+}
 
-	if len(bugFixes) > 0 {
-		sb.WriteString("### Bug Fixes\n\n")
-		for _, fix := range bugFixes {
-			sb.WriteString(fix + "\n")
-		}
-		sb.WriteString("\n")
+// matches reports whether the commit satisfies all conditions of the rule.
+func (r BumpRule) matches(commit Commit) bool {
+	if r.Type != "" && r.Type != "*" && r.Type != commit.Type {
+		return false
 	}
-
-	if len(otherChanges) > 0 {
-		sb.WriteString("### Other Changes\n\n")
-		for _, other := range otherChanges {
-			sb.WriteString(other + "\n")
+	if r.Scope != "" {
+		if ok, _ := path.Match(r.Scope, commit.Scope); !ok {
+			return false
 		}
-		sb.WriteString("\n")
 	}
-
-	newChangelogContent := sb.String()
-
-	// Read existing changelog content if file exists
-	var existingContent []byte
-	if _, err := os.Stat(outputPath); err == nil { // File exists
-		existingContent, err = ioutil.ReadFile(outputPath)
-		if err != nil {
-			return fmt.Errorf("failed to read existing changelog file '%s': %v", outputPath, err)
+	if r.Footer != "" {
+		if _, ok := commit.FooterValue(r.Footer); !ok {
+			return false
 		}
 	}
-
-	// Prepend new content to existing content
-	finalContent := []byte(newChangelogContent + string(existingContent))
-
-	// Write to file
-	if err := ioutil.WriteFile(outputPath, finalContent, 0644); err != nil {
-		return fmt.Errorf("failed to write changelog to file '%s': %v", outputPath, err)
-	}
-
-	return nil
+	return true
 }
 
-func getCurrentVersion() (*semver.Version, error) {
-	// Get all tags
-	out, err := exec.Command("git", "tag", "-l", "v*", "--sort=-v:refname").Output()
-	if err != nil {
-		return nil, fmt.Errorf("error getting git tags: %v", err)
-	}
-
-	tags := strings.Fields(string(out))
-
+// getCurrentVersion returns the tag of the highest version among the given version tags.
+// If there is none, an unnamed tag of version 0.0.0 is returned.
+func getCurrentVersion(tags []versionTag) versionTag {
 	// Find the highest version tag
-	var latestVersion *semver.Version
+	var latest versionTag
 	for _, tag := range tags {
-		tag = strings.TrimSpace(tag)
-		if strings.HasPrefix(tag, "v") {
-			verStr := strings.TrimPrefix(tag, "v")
-			v, err := semver.NewVersion(verStr)
-			if err == nil {
-				if latestVersion == nil || v.GreaterThan(latestVersion) {
-					latestVersion = v
-				}
-			}
+		if latest.Version == nil || tag.Version.GreaterThan(latest.Version) {
+			latest = tag
 		}
 	}
 
-	if latestVersion != nil {
-		return latestVersion, nil
+	if latest.Version != nil {
+		return latest
 	}
 
 	// If no valid version tags found, start with 0.0.0
-	return semver.NewVersion("0.0.0")
+	v, _ := semver.NewVersion("0.0.0")
+	return versionTag{Version: v}
 }
 
-func calculateNewVersion(current *semver.Version, bumpType, branch string, preRelease bool) (string, error) {
-	if !preRelease {
-		// If not a pre-release, simply increment the current version based on bumpType
-		switch bumpType {
-		case "major":
-			return current.IncMajor().String(), nil
-		case "minor":
-			return current.IncMinor().String(), nil
-		default: // patch
-			return current.IncPatch().String(), nil
-		}
+// calculateNewVersion applies the bump to the base version, the last release. Releases and
+// pre-releases are the last release plus the highest bump since it, e.g. 1.4.0-rc.N and then
+// 1.4.0 after 1.3.2 with a feat commit; only the counter N increments between pre-releases of
+// the same base version.
+func calculateNewVersion(base *semver.Version, bumpType string, preRelease bool, identifier string, tags []versionTag) (string, error) {
+	var newVer semver.Version
+	switch bumpType {
+	case "major":
+		newVer = base.IncMajor()
+	case "minor":
+		newVer = base.IncMinor()
+	default: // patch
+		newVer = base.IncPatch()
 	}
 
-	// Handle pre-release versioning
-	sanitizedBranch := regexp.MustCompile(`[^a-zA-Z0-9-]`).ReplaceAllString(branch, "-")
+	if !preRelease {
+		return newVer.String(), nil
+	}
 
-	// 1. Find the highest pre-release tag belonging to the current branch
-	branchPreReleasePattern := regexp.QuoteMeta("v") + `\d+\.\d+\.\d+-` + regexp.QuoteMeta(sanitizedBranch) + `\.(\d+)$`
-	branchPreReleaseRegex := regexp.MustCompile(branchPreReleasePattern)
-	var highestBranchPreRelease *semver.Version
-	var highestBranchPreReleaseNum = -1
+	newVer, err := nextPrerelease(newVer, identifier, tags)
+	if err != nil {
+		return "", err
+	}
+	return newVer.String(), nil
+}
 
-	out, err := exec.Command("git", "tag", "-l", "--sort=-v:refname").Output()
-	if err == nil {
-		for _, tag := range strings.Fields(string(out)) {
-			matches := branchPreReleaseRegex.FindStringSubmatch(tag)
-			if len(matches) > 1 {
-				num, err := strconv.Atoi(matches[1])
-				if err == nil {
-					v, err := semver.NewVersion(strings.TrimPrefix(tag, "v"))
-					if err == nil && num > highestBranchPreReleaseNum {
-						highestBranchPreRelease = v
-						highestBranchPreReleaseNum = num
-					}
-				}
-			}
-		}
+// nextPrerelease returns the next pre-release of base in the channel. The counter continues from
+// the highest pre-release of the same base version and channel, so it starts over at 0 whenever
+// the base version changes.
+func nextPrerelease(base semver.Version, identifier string, tags []versionTag) (semver.Version, error) {
+	newPreRelease := fmt.Sprintf("%s.%d", identifier, nextPrereleaseNumber(tags, base, identifier))
+	v, err := base.SetPrerelease(newPreRelease)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid pre-release identifier '%s': %v", newPreRelease, err)
 	}
+	return v, nil
+}
 
-	if highestBranchPreRelease != nil {
-		// If a branch-specific pre-release tag was found, increment its pre-release number
-		newPreRelease := fmt.Sprintf("%s.%d", sanitizedBranch, highestBranchPreReleaseNum+1)
-		newVer := *highestBranchPreRelease // Start with the found highest pre-release version
+// prereleaseNumber returns the counter of a pre-release version of the given channel
+// identifier, e.g. 3 for 1.2.0-rc.3 and identifier "rc".
+func prereleaseNumber(v *semver.Version, identifier string) (int, bool) {
+	counter, ok := strings.CutPrefix(v.Prerelease(), identifier+".")
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.Atoi(counter)
+	if err != nil || num < 0 {
+		return 0, false
+	}
+	return num, true
+}
 
-		// Apply the bump type to the base version (major.minor.patch) of the highest pre-release
-		// and then re-apply the new pre-release suffix.
-		var bumpedBase semver.Version
-		switch bumpType {
-		case "major":
-			bumpedBase = newVer.IncMajor()
-		case "minor":
-			bumpedBase = newVer.IncMinor()
-		case "patch":
-			bumpedBase = newVer.IncPatch()
-		default:
-			// If bumpType is not major/minor/patch, just take the base of current pre-release
-			// and attach the incremented pre-release suffix.
-			base := fmt.Sprintf("%d.%d.%d", newVer.Major(), newVer.Minor(), newVer.Patch())
-			v, _ := semver.NewVersion(base)
-			bumpedBase = *v
+// nextPrereleaseNumber returns the counter of the next pre-release of base in the channel.
+func nextPrereleaseNumber(tags []versionTag, base semver.Version, identifier string) int {
+	next := 0
+	for _, tag := range tags {
+		v := tag.Version
+		if v.Major() != base.Major() || v.Minor() != base.Minor() || v.Patch() != base.Patch() {
+			continue
 		}
-		newVer, _ = bumpedBase.SetPrerelease(newPreRelease)
-		return newVer.String(), nil
-	} else {
-		// No pre-release tags found for this branch.
-		// Get the latest *release* version (non-pre-release) from the entire repository.
-		latestRelease, err := getCurrentReleaseVersion()
-		if err != nil {
-			latestRelease, _ = semver.NewVersion("0.0.0") // Fallback if no release versions found
+		if num, ok := prereleaseNumber(v, identifier); ok && num >= next {
+			next = num + 1
 		}
-
-		// Create the first pre-release for this branch based on the latest release version.
-		// We do NOT apply the bumpType here, as the first pre-release should be based on the
-		// exact release version it branched off from (e.g., v0.2.0-demo.0 if branched from v0.2.0).
-		newPreRelease := fmt.Sprintf("%s.0", sanitizedBranch)
-		newVer, _ := latestRelease.SetPrerelease(newPreRelease)
-		return newVer.String(), nil
 	}
+	return next
 }
 
-// getCurrentReleaseVersion finds the highest non-pre-release tag.
-func getCurrentReleaseVersion() (*semver.Version, error) {
-	out, err := exec.Command("git", "tag", "-l", "v*", "--sort=-v:refname").Output()
-	if err != nil {
-		return nil, fmt.Errorf("error getting git tags: %v", err)
+// latestPrerelease returns the tag of the highest pre-release of the channel identifier.
+func latestPrerelease(tags []versionTag, identifier string) (versionTag, bool) {
+	for _, tag := range tags { // Highest version first
+		if _, ok := prereleaseNumber(tag.Version, identifier); ok {
+			return tag, true
+		}
 	}
+	return versionTag{}, false
+}
 
-	tags := strings.Fields(string(out))
+// getCurrentReleaseVersion finds the tag of the highest non-pre-release version.
+// If there is none, an unnamed tag of version 0.0.0 is returned.
+func getCurrentReleaseVersion(tags []versionTag) versionTag {
+	var releases []versionTag
 	for _, tag := range tags {
-		tag = strings.TrimSpace(tag)
-		if strings.HasPrefix(tag, "v") {
-			verStr := strings.TrimPrefix(tag, "v")
-			v, err := semver.NewVersion(verStr)
-			if err == nil && v.Prerelease() == "" { // Only consider release versions (no pre-release suffix)
-				return v, nil
-			}
+		if tag.Version.Prerelease() == "" { // Only consider release versions (no pre-release suffix)
+			releases = append(releases, tag)
 		}
 	}
-	// If no valid release tags found, start with 0.0.0
-	v, _ := semver.NewVersion("0.0.0")
-	return v, nil
+	return getCurrentVersion(releases)
 }
 
 // isDefaultBranch checks if the given branch is the default branch of the repository
-func isDefaultBranch(branch string) bool {
+func isDefaultBranch(remote, branch string) bool {
 	// First try to get the default branch from remote
-	cmd := exec.Command("git", "remote", "show", "origin")
+	cmd := exec.Command("git", "remote", "show", remote)
 	out, err := cmd.CombinedOutput()
 	if err == nil {
 		// Look for "HEAD branch: branch_name" in the output
@@ -765,8 +604,9 @@ func tagExists(tagName string) bool {
 	return len(strings.TrimSpace(string(out))) > 0
 }
 
-// pushCurrentBranch pushes the current branch to the remote
-func pushCurrentBranch() error {
+// pushCurrentBranch pushes the current branch to the remote. In a detached checkout,
+// HEAD is pushed to the given branch.
+func pushCurrentBranch(remote, branch string) error {
 	// Get current branch name
 	branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	branchOut, err := branchCmd.Output()
@@ -776,7 +616,11 @@ func pushCurrentBranch() error {
 	branchName := strings.TrimSpace(string(branchOut))
 
 	// Push the branch with --set-upstream
-	pushCmd := exec.Command("git", "push", "--set-upstream", "origin", branchName)
+	pushCmd := exec.Command("git", "push", "--set-upstream", remote, branchName)
+	if branchName == "HEAD" {
+		// Detached checkout of a CI build, push to the branch being built
+		pushCmd = exec.Command("git", "push", remote, "HEAD:refs/heads/"+branch)
+	}
 	pushCmd.Stdout = os.Stdout
 	pushCmd.Stderr = os.Stderr
 
@@ -787,15 +631,15 @@ func pushCurrentBranch() error {
 	return nil
 }
 
-func pushTag(tagName string) error {
+func pushTag(remote, tagName string) error {
 	// Check if remote exists
 	out, err := exec.Command("git", "remote").Output()
 	if err != nil || len(strings.TrimSpace(string(out))) == 0 {
-		return fmt.Errorf("no remote repository configured. Please add a remote with 'git remote add origin <url>'")
+		return fmt.Errorf("no remote repository configured. Please add a remote with 'git remote add %s <url>'", remote)
 	}
 
 	// Try to push with dry-run first, but don't fail if it doesn't work
-	testPush := exec.Command("git", "push", "--dry-run", "--no-verify", "origin", tagName)
+	testPush := exec.Command("git", "push", "--dry-run", "--no-verify", remote, tagName)
 	if err := testPush.Run(); err != nil {
 		fmt.Printf("Dry-run push failed, will attempt actual push: %v\n", err)
 	}
@@ -803,7 +647,7 @@ func pushTag(tagName string) error {
 	// Push the tag to remote with retry logic
 	maxRetries := 2
 	for i := 0; i <= maxRetries; i++ {
-		pushCmd := exec.Command("git", "push", "origin", tagName)
+		pushCmd := exec.Command("git", "push", remote, tagName)
 		pushCmd.Stdout = os.Stdout
 		pushCmd.Stderr = os.Stderr
 
@@ -825,7 +669,7 @@ func pushTag(tagName string) error {
 	branchOut, err := branchCmd.Output()
 	if err == nil {
 		branchName := strings.TrimSpace(string(branchOut))
-		trackCmd := exec.Command("git", "push", "--set-upstream", "origin", branchName)
+		trackCmd := exec.Command("git", "push", "--set-upstream", remote, branchName)
 		trackCmd.Stdout = os.Stdout
 		trackCmd.Stderr = os.Stderr
 		// We don't fail if this command fails, as the tag push was successful
@@ -836,27 +680,21 @@ func pushTag(tagName string) error {
 }
 
 // shouldSkipCI checks if the commit message contains [skip-ci] or similar patterns
-func shouldSkipCI(commitMsg string) bool {
-	// Check for common skip-ci patterns (case insensitive)
-	skipPatterns := []string{
-		"[skip-ci]",
-		"[ci skip]",
-		"skip-checks: true",
-	}
-
+func shouldSkipCI(commitMsg string, skipPatterns []string) bool {
+	// Check for the configured skip-ci patterns (case insensitive)
 	commitMsg = strings.ToLower(commitMsg)
 	for _, pattern := range skipPatterns {
-		if strings.Contains(commitMsg, pattern) {
+		if strings.Contains(commitMsg, strings.ToLower(pattern)) {
 			return true
 		}
 	}
 	return false
 }
 
-func createGitTag(tagName string) error {
-	// Create annotated tag with a message that includes [skip-ci]
-	tagMessage := fmt.Sprintf("Release %s [skip-ci]", tagName)
-	cmd := exec.Command("git", "tag", "-a", tagName, "-m", tagMessage)
+// createGitTag creates an annotated tag on the given commit
+func createGitTag(tagName, tagMessage, commit string) error {
+	// Create annotated tag, the default message includes [skip-ci]
+	cmd := exec.Command("git", "tag", "-a", tagName, "-m", tagMessage, commit)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -878,22 +716,20 @@ func createGitTag(tagName string) error {
 	return nil
 }
 
-// addAndCommitChangelog adds the changelog file to git and commits it.
-func addAndCommitChangelog(changelogPath, tagName string) error {
-	// git add CHANGELOG.md
-	addCmd := exec.Command("git", "add", changelogPath)
+// addAndCommitReleaseFiles adds the files changed for a release (e.g. the changelog) to git and commits them.
+func addAndCommitReleaseFiles(paths []string, commitMessage string) error {
+	// git add CHANGELOG.md ...
+	addCmd := exec.Command("git", append([]string{"add", "--"}, paths...)...)
 	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("error adding changelog to git: %v", err)
+		return fmt.Errorf("error adding release files to git: %v", err)
 	}
 
 	// git commit -m "chore(release): update changelog for vX.Y.Z [skip-ci]"
-	commitMessage := fmt.Sprintf("chore(release): update changelog for %s [skip-ci]", tagName)
 	commitCmd := exec.Command("git", "commit", "-m", commitMessage)
 	commitCmd.Stdout = os.Stdout
 	commitCmd.Stderr = os.Stderr
 	if err := commitCmd.Run(); err != nil {
-		return fmt.Errorf("error committing changelog: %v", err)
+		return fmt.Errorf("error committing release files: %v", err)
 	}
 	return nil
 }
-
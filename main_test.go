@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// testTags builds version tags named v<version>, in the given order.
+func testTags(versions ...string) []versionTag {
+	tags := make([]versionTag, 0, len(versions))
+	for _, version := range versions {
+		tags = append(tags, versionTag{Name: "v" + version, Version: semver.MustParse(version)})
+	}
+	return tags
+}
+
+func TestCalculateNewVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		base       string
+		bump       string
+		preRelease bool
+		identifier string
+		tags       []versionTag
+		want       string
+	}{
+		{name: "patch release", base: "1.3.2", bump: "patch", want: "1.3.3"},
+		{name: "minor release", base: "1.3.2", bump: "minor", want: "1.4.0"},
+		{name: "major release", base: "1.3.2", bump: "major", want: "2.0.0"},
+		{name: "minor release resets patch", base: "1.3.2", bump: "minor", tags: testTags("1.4.0-rc.0", "1.3.2"), want: "1.4.0"},
+		{name: "release ignores pre-releases", base: "1.3.2", bump: "minor", tags: testTags("1.4.0-rc.3", "1.3.2"), want: "1.4.0"},
+		{name: "first pre-release", base: "1.3.2", bump: "minor", preRelease: true, identifier: "rc", tags: testTags("1.3.2"), want: "1.4.0-rc.0"},
+		{name: "next pre-release of the same base", base: "1.3.2", bump: "minor", preRelease: true, identifier: "rc", tags: testTags("1.4.0-rc.1", "1.4.0-rc.0", "1.3.2"), want: "1.4.0-rc.2"},
+		{name: "higher bump starts over", base: "1.3.2", bump: "major", preRelease: true, identifier: "rc", tags: testTags("1.4.0-rc.1", "1.3.2"), want: "2.0.0-rc.0"},
+		{name: "patch pre-release", base: "1.3.0", bump: "patch", preRelease: true, identifier: "beta", tags: testTags("1.3.0"), want: "1.3.1-beta.0"},
+		{name: "other channels keep their counters", base: "1.3.2", bump: "minor", preRelease: true, identifier: "beta", tags: testTags("1.4.0-rc.4", "1.4.0-beta.0", "1.3.2"), want: "1.4.0-beta.1"},
+		{name: "branch identifier", base: "0.0.0", bump: "minor", preRelease: true, identifier: "feature-login", want: "0.1.0-feature-login.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateNewVersion(semver.MustParse(tt.base), tt.bump, tt.preRelease, tt.identifier, tt.tags)
+			if err != nil {
+				t.Fatalf("calculateNewVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("calculateNewVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateNewVersionInvalidIdentifier(t *testing.T) {
+	if _, err := calculateNewVersion(semver.MustParse("1.0.0"), "patch", true, "not valid", nil); err == nil {
+		t.Error("calculateNewVersion() with an invalid identifier did not fail")
+	}
+}
+
+func TestNextPrereleaseNumber(t *testing.T) {
+	tests := []struct {
+		name       string
+		tags       []versionTag
+		base       string
+		identifier string
+		want       int
+	}{
+		{name: "no tags", base: "1.4.0", identifier: "rc", want: 0},
+		{name: "only releases", tags: testTags("1.4.0", "1.3.2"), base: "1.4.0", identifier: "rc", want: 0},
+		{name: "continues the counter", tags: testTags("1.4.0-rc.1", "1.4.0-rc.0"), base: "1.4.0", identifier: "rc", want: 2},
+		{name: "gaps continue from the highest", tags: testTags("1.4.0-rc.7", "1.4.0-rc.2"), base: "1.4.0", identifier: "rc", want: 8},
+		{name: "other base version", tags: testTags("1.3.1-rc.5"), base: "1.4.0", identifier: "rc", want: 0},
+		{name: "other channel", tags: testTags("1.4.0-beta.3"), base: "1.4.0", identifier: "rc", want: 0},
+		{name: "identifier prefix of another", tags: testTags("1.4.0-rc-2.4"), base: "1.4.0", identifier: "rc", want: 0},
+		{name: "non-numeric counter", tags: testTags("1.4.0-rc.x"), base: "1.4.0", identifier: "rc", want: 0},
+		{name: "dotted identifier", tags: testTags("1.4.0-feature.login.1"), base: "1.4.0", identifier: "feature.login", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPrereleaseNumber(tt.tags, *semver.MustParse(tt.base), tt.identifier); got != tt.want {
+				t.Errorf("nextPrereleaseNumber() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestPrerelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		tags       []versionTag
+		identifier string
+		want       string // Empty if none is found
+	}{
+		{name: "no tags", identifier: "rc"},
+		{name: "only releases", tags: testTags("1.4.0", "1.3.2"), identifier: "rc"},
+		{name: "highest of the channel", tags: testTags("1.4.0-rc.1", "1.4.0-rc.0", "1.3.2"), identifier: "rc", want: "v1.4.0-rc.1"},
+		{name: "skips other channels", tags: testTags("2.0.0-beta.0", "1.4.0-rc.1", "1.3.2"), identifier: "rc", want: "v1.4.0-rc.1"},
+		{name: "older base version", tags: testTags("1.4.0", "1.4.0-rc.1", "1.3.2"), identifier: "rc", want: "v1.4.0-rc.1"},
+		{name: "other channel only", tags: testTags("1.4.0-beta.0"), identifier: "rc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := latestPrerelease(tt.tags, tt.identifier)
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("latestPrerelease() = %q, %v, want %q", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// MaintenanceConfig maps a maintenance branch of an older version line to the versions it may release.
+// Maintenance branches release final versions, the current version is taken from the tags reachable
+// on the branch, and a bump crossing the range fails.
+type MaintenanceConfig struct {
+	Branch string `yaml:"branch" toml:"branch"` // Glob matched against the branch name, e.g. "release/1.x"
+	Range  string `yaml:"range" toml:"range"`   // Allowed versions, e.g. ">=1.0.0 <2.0.0"
+}
+
+// maintenanceFor returns the maintenance branch definition matching the branch.
+func (c *Config) maintenanceFor(branch string) (MaintenanceConfig, bool) {
+	for _, m := range c.Maintenance {
+		if ok, _ := path.Match(m.Branch, branch); ok {
+			return m, true
+		}
+	}
+	return MaintenanceConfig{}, false
+}
+
+// checkVersionRange fails if the version, ignoring its pre-release, is outside the version range
+// of the maintenance branch being released.
+func (c *Config) checkVersionRange(v *semver.Version) error {
+	if c.VersionRange == "" {
+		return nil
+	}
+	core, _ := v.SetPrerelease("")
+	constraint, err := semver.NewConstraint(c.VersionRange)
+	if err != nil {
+		return fmt.Errorf("Error parsing version range '%s': %v", c.VersionRange, err)
+	}
+	if !constraint.Check(&core) {
+		return fmt.Errorf("Error: version %s is outside the range '%s' of maintenance branch %s. Release it from a branch of the next version line, or use -set-version with a version in range", c.versionScheme().formatVersion(v), c.VersionRange, c.Branch)
+	}
+	return nil
+}
+
+// validateMaintenance checks the maintenance branch definitions.
+func (c *Config) validateMaintenance() []configError {
+	var errs []configError
+	for i, m := range c.Maintenance {
+		key := fmt.Sprintf("maintenance.%d", i)
+		if m.Branch == "" {
+			errs = append(errs, configError{Key: key, Message: "a maintenance branch needs a branch pattern"})
+		} else if _, err := path.Match(m.Branch, ""); err != nil {
+			errs = append(errs, configError{Key: key + ".branch", Message: fmt.Sprintf("invalid branch pattern %q", m.Branch)})
+		}
+		if m.Range == "" {
+			errs = append(errs, configError{Key: key, Message: "a maintenance branch needs a version range, e.g. \">=1.0.0 <2.0.0\""})
+		} else if _, err := semver.NewConstraint(m.Range); err != nil {
+			errs = append(errs, configError{Key: key + ".range", Message: fmt.Sprintf("invalid version range %q: %v", m.Range, err)})
+		}
+	}
+	return errs
+}
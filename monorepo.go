@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PackageConfig is an independently versioned package of a monorepo.
+type PackageConfig struct {
+	Name         string              `yaml:"name" toml:"name"`
+	Paths        []string            `yaml:"paths" toml:"paths"`                               // Globs of the files belonging to the package, '!' excludes
+	TagPrefix    string              `yaml:"tag_prefix,omitempty" toml:"tag_prefix,omitempty"` // Default: <package dir>/v
+	TagFormat    string              `yaml:"tag_format,omitempty" toml:"tag_format,omitempty"` // Default: <tag_prefix><version>
+	Changelog    string              `yaml:"changelog,omitempty" toml:"changelog,omitempty"`   // Default: <package dir>/CHANGELOG.md
+	VersionFiles []VersionFileConfig `yaml:"version_files,omitempty" toml:"version_files,omitempty"`
+}
+
+// dir returns the directory of the package, derived from the static part of its first path glob.
+func (p PackageConfig) dir() string {
+	for _, glob := range p.Paths {
+		if strings.HasPrefix(glob, "!") {
+			continue
+		}
+		static := glob
+		if i := strings.IndexAny(glob, "*?["); i >= 0 {
+			static = path.Dir(glob[:i] + "x") // Drop the partial path element containing the wildcard
+		}
+		return strings.Trim(path.Clean(static), "/")
+	}
+	return "."
+}
+
+// packageTarget returns the release target of a monorepo package, filling in the defaults.
+func packageTarget(cfg *Config, pkg PackageConfig) releaseTarget {
+	dir := pkg.dir()
+
+	target := releaseTarget{
+		Name:          pkg.Name,
+		Dir:           dir,
+		Paths:         pkg.Paths,
+		TagPrefix:     pkg.TagPrefix,
+		TagFormat:     pkg.TagFormat,
+		ChangelogPath: pkg.Changelog,
+		VersionFiles:  pkg.VersionFiles,
+		Scheme:        cfg.versionScheme(),
+	}
+	if target.TagPrefix == "" {
+		if dir == "." {
+			target.TagPrefix = pkg.Name + "/v"
+		} else {
+			target.TagPrefix = dir + "/v"
+		}
+	}
+	if target.ChangelogPath == "" {
+		target.ChangelogPath = path.Join(dir, path.Base(cfg.Changelog.Path))
+	}
+	return target
+}
+
+// findPackage returns the package with the given name.
+func (c *Config) findPackage(name string) (PackageConfig, bool) {
+	for _, pkg := range c.Packages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return PackageConfig{}, false
+}
+
+// validatePackages checks the package definitions of a monorepo.
+func (c *Config) validatePackages() []configError {
+	var errs []configError
+	names := map[string]bool{}
+	prefixes := map[string]string{}
+
+	for i, pkg := range c.Packages {
+		key := fmt.Sprintf("packages.%d", i)
+		if strings.TrimSpace(pkg.Name) == "" {
+			errs = append(errs, configError{Key: key, Message: "package name must not be empty"})
+		} else if names[pkg.Name] {
+			errs = append(errs, configError{Key: key + ".name", Message: fmt.Sprintf("duplicate package name %q", pkg.Name)})
+		}
+		names[pkg.Name] = true
+
+		if len(pkg.Paths) == 0 {
+			errs = append(errs, configError{Key: key, Message: fmt.Sprintf("package %q needs at least one path", pkg.Name)})
+		}
+		for j, glob := range pkg.Paths {
+			if _, err := path.Match(strings.TrimPrefix(glob, "!"), ""); err != nil || strings.HasPrefix(glob, "/") {
+				errs = append(errs, configError{Key: fmt.Sprintf("%s.paths.%d", key, j), Message: fmt.Sprintf("invalid path glob %q, paths are relative to the repository root", glob)})
+			}
+		}
+
+		errs = append(errs, validateVersionFiles(pkg.VersionFiles, key+".version_files")...)
+
+		if _, err := parseTagFormat(pkg.TagFormat); err != nil {
+			errs = append(errs, configError{Key: key + ".tag_format", Message: fmt.Sprintf("invalid template: %v", err)})
+		}
+
+		prefix := packageTarget(c, pkg).TagPrefix
+		if other, ok := prefixes[prefix]; ok {
+			errs = append(errs, configError{Key: key + ".tag_prefix", Message: fmt.Sprintf("tag prefix %q is already used by package %q", prefix, other)})
+		}
+		prefixes[prefix] = pkg.Name
+	}
+	return errs
+}
+
+// runReleaseAll implements the "release-all" command: every package with commits
+// since its last release is released independently in one run.
+func runReleaseAll(cfg *Config, opts releaseOptions) error {
+	if len(cfg.Packages) == 0 {
+		return withCode(errCodeConfig, fmt.Errorf("no packages defined in the config file"))
+	}
+	if opts.SetVersion != "" {
+		return withCode(errCodeUsage, fmt.Errorf("-set-version cannot be used with release-all, use -package to release a single package"))
+	}
+
+	if ok, err := prepareRelease(cfg); err != nil || !ok {
+		return err
+	}
+
+	var released []string
+	for _, pkg := range cfg.Packages {
+		fmt.Printf("\n=== Package %s ===\n", pkg.Name)
+		tagName, err := release(cfg, packageTarget(cfg, pkg), opts)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", pkg.Name, err)
+		}
+		if tagName == "" {
+			continue
+		}
+		released = append(released, tagName)
+		if opts.NextVersionOnly {
+			fmt.Printf("%s %s\n", pkg.Name, tagName)
+		}
+	}
+
+	if len(released) == 0 {
+		fmt.Println("\nNo package needs a release.")
+	} else if !opts.NextVersionOnly {
+		fmt.Printf("\nReleased %d package(s): %s\n", len(released), strings.Join(released, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runNotesCommand implements the "notes" command: the release notes of a version, of a range of
+// commits, or of the changes since the latest release are rendered to stdout or a file. Unlike
+// the changelog, this works for pre-releases as well, and nothing is committed.
+func runNotesCommand(cfg *Config, target releaseTarget, args []string) error {
+	fs := flag.NewFlagSet("notes", flag.ContinueOnError)
+	file := fs.String("file", "", "Write the release notes to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return withCode(errCodeUsage, err)
+	}
+	if fs.NArg() > 1 {
+		return withCode(errCodeUsage, fmt.Errorf("usage: semvergo [flags] notes [-file path] [<tag> | <from>..<to>]"))
+	}
+
+	matcher := target.tagMatcher()
+	tags, err := listVersionTags(matcher)
+	if err != nil {
+		return fmt.Errorf("error getting version tags: %v", err)
+	}
+
+	var from, to string
+	switch arg := fs.Arg(0); {
+	case strings.Contains(arg, ".."):
+		from, to = splitRange(arg)
+	case arg != "":
+		tag, ok := findVersionTag(tags, arg)
+		if !ok {
+			return withCode(errCodeNotFound, fmt.Errorf("%s is not a version tag of the %s, looking for %s", arg, target.label(), matcher.describe()))
+		}
+		to = tag.Name
+		if from, err = previousVersionTag(cfg, tags, tag); err != nil {
+			return err
+		}
+	default:
+		// The release on HEAD, e.g. right after it was created, or the unreleased changes
+		to = "HEAD"
+		if tag, ok := versionTagAt(tags, "HEAD"); ok {
+			to = tag.Name
+			if from, err = previousVersionTag(cfg, tags, tag); err != nil {
+				return err
+			}
+		} else {
+			history, err := reachableTags(tags, "HEAD", cfg.FirstParent)
+			if err != nil {
+				return err
+			}
+			from = getCurrentReleaseVersion(history).Name
+		}
+	}
+
+	data := changelogData{Tag: to, Version: to, PreviousTag: from, PreviousVersion: from}
+	if to == "HEAD" {
+		data.Tag, data.Version = "Unreleased", "Unreleased"
+	}
+	if v, ok := matcher.parse(to); ok {
+		data.Version = target.Scheme.formatVersion(v)
+		dates, err := tagDates()
+		if err != nil {
+			return err
+		}
+		data.Date = dates[to]
+	}
+	if v, ok := matcher.parse(from); ok {
+		data.PreviousVersion = target.Scheme.formatVersion(v)
+	}
+
+	commits, err := getCommitsBetweenRefs(from, to, target.Paths...)
+	if err != nil {
+		return fmt.Errorf("error getting commits for release notes: %v", err)
+	}
+	notes, err := renderReleaseNotes(cfg, commits, data)
+	if err != nil {
+		return err
+	}
+
+	report.Notes = notes
+	if *file == "" {
+		fmt.Print(notes)
+		return nil
+	}
+	if err := os.WriteFile(*file, []byte(notes), 0644); err != nil {
+		return fmt.Errorf("failed to write release notes to file '%s': %v", *file, err)
+	}
+	fmt.Printf("Release notes of %s written to %s\n", data.Tag, *file)
+	report.action("notes", actionPerformed, *file)
+	return nil
+}
+
+// splitRange splits a "<from>..<to>" range, an empty end defaults to HEAD.
+func splitRange(commitRange string) (string, string) {
+	from, to, _ := strings.Cut(commitRange, "..")
+	if to == "" {
+		to = "HEAD"
+	}
+	return from, to
+}
+
+// findVersionTag returns the version tag with the given tag name or version.
+func findVersionTag(tags []versionTag, name string) (versionTag, bool) {
+	for _, tag := range tags {
+		if tag.Name == name || tag.Version.String() == strings.TrimPrefix(name, "v") {
+			return tag, true
+		}
+	}
+	return versionTag{}, false
+}
+
+// versionTagAt returns the highest version tag pointing to the commit of ref.
+func versionTagAt(tags []versionTag, ref string) (versionTag, bool) {
+	out, err := exec.Command("git", "tag", "--points-at", ref).Output()
+	if err != nil {
+		return versionTag{}, false
+	}
+	names := strings.Fields(string(out))
+	for _, tag := range tags { // Highest version first
+		if containsString(names, tag.Name) {
+			return tag, true
+		}
+	}
+	return versionTag{}, false
+}
+
+// previousVersionTag returns the tag the release notes of a version start from: the previous
+// final release for a release, as its notes cover all pre-releases since, and the previous
+// version of any kind for a pre-release. It is empty for the first version.
+func previousVersionTag(cfg *Config, tags []versionTag, tag versionTag) (string, error) {
+	history, err := reachableTags(tags, tag.Name, cfg.FirstParent)
+	if err != nil {
+		return "", err
+	}
+	for _, previous := range history { // Highest version first
+		if !previous.Version.LessThan(tag.Version) {
+			continue
+		}
+		if tag.Version.Prerelease() != "" || previous.Version.Prerelease() == "" {
+			return previous.Name, nil
+		}
+	}
+	return "", nil
+}
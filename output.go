@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Stable error codes of the machine-readable output, see -output json.
+const (
+	errCodeFailed          = "failed" // Any error without a more specific code
+	errCodeUsage           = "usage"
+	errCodeConfig          = "config_invalid"
+	errCodeNotRepository   = "not_a_repository"
+	errCodeGitConfig       = "git_config"
+	errCodeDirtyWorktree   = "dirty_worktree"
+	errCodeCommitMessage   = "invalid_commit_message"
+	errCodeInvalidVersion  = "invalid_version"
+	errCodeVersionRange    = "version_out_of_range"
+	errCodeTagExists       = "tag_exists"
+	errCodeGoModule        = "go_module_major_version"
+	errCodeNotFound        = "not_found"
+	errCodeChangelogFormat = "changelog_unrecognized"
+	errCodeCommit          = "commit_failed"
+	errCodeTag             = "tag_failed"
+	errCodePush            = "push_failed"
+)
+
+// codedError is an error with a stable code for the machine-readable output.
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// withCode attaches a stable code to an error.
+func withCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// errorCode returns the code of an error, see withCode.
+func errorCode(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return errCodeFailed
+}
+
+// Statuses of the actions of a run.
+const (
+	actionPerformed = "performed"
+	actionSkipped   = "skipped"
+	actionDryRun    = "dry_run" // Would have been performed without -dry-run
+	actionFailed    = "failed"  // Failed without failing the run, e.g. the changelog of a release
+)
+
+// runReport is the result of a run, written as a single JSON document with -output json.
+type runReport struct {
+	Command  string           `json:"command"` // release, release-all, promote, notes, changelog or config
+	Success  bool             `json:"success"`
+	DryRun   bool             `json:"dry_run"`
+	Branch   string           `json:"branch,omitempty"`
+	Releases []*releaseReport `json:"releases"`
+	Actions  []actionReport   `json:"actions,omitempty"` // Actions of commands other than releases
+	Notes    string           `json:"notes,omitempty"`   // Release notes rendered by the notes command
+	Config   interface{}      `json:"config,omitempty"`  // Effective configuration of the config command
+	Error    *errorReport     `json:"error,omitempty"`
+
+	out io.Writer // Destination of the JSON document, nil in text mode
+}
+
+// releaseReport is the result of the release of a single target.
+type releaseReport struct {
+	Package         string         `json:"package,omitempty"`
+	PreviousVersion string         `json:"previous_version,omitempty"`
+	PreviousTag     string         `json:"previous_tag,omitempty"`
+	NextVersion     string         `json:"next_version,omitempty"` // Empty if no release is needed
+	Tag             string         `json:"tag,omitempty"`
+	Bump            string         `json:"bump,omitempty"`
+	Prerelease      bool           `json:"prerelease"`
+	Commits         []commitReport `json:"commits"` // Commits causing the bump
+	ChangelogPath   string         `json:"changelog_path,omitempty"`
+	Actions         []actionReport `json:"actions"`
+}
+
+// commitReport is a commit causing a version bump.
+type commitReport struct {
+	SHA      string `json:"sha"`
+	Header   string `json:"header"`
+	Type     string `json:"type,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	Breaking bool   `json:"breaking"`
+	Bump     string `json:"bump"` // Bump of the commit, lowered like the release during initial development
+}
+
+// actionReport is a step of a run, e.g. creating the tag.
+type actionReport struct {
+	Name   string `json:"name"` // e.g. changelog, version_file, go_module, commit, tag, push_tag, push_branch
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"` // e.g. the tag name, or why the action was skipped
+}
+
+// errorReport is the error that failed a run.
+type errorReport struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// report collects the result of the current run.
+var report = &runReport{Releases: []*releaseReport{}}
+
+// enableJSON switches to the machine-readable output: the report is written to stdout at the end
+// of the run, and everything else printed to stdout goes to stderr instead.
+func (r *runReport) enableJSON() {
+	r.out = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// startRelease adds the report of the release of a target.
+func (r *runReport) startRelease(target releaseTarget) *releaseReport {
+	release := &releaseReport{Package: target.Name, ChangelogPath: target.ChangelogPath, Commits: []commitReport{}, Actions: []actionReport{}}
+	r.Releases = append(r.Releases, release)
+	return release
+}
+
+// action records an action of a command other than a release.
+func (r *runReport) action(name, status, detail string) {
+	r.Actions = append(r.Actions, actionReport{Name: name, Status: status, Detail: detail})
+}
+
+// action records an action of the release.
+func (r *releaseReport) action(name, status, detail string) {
+	r.Actions = append(r.Actions, actionReport{Name: name, Status: status, Detail: detail})
+}
+
+// setCommits records the commits causing the bump, see determineBumpType. The bump of each
+// commit is passed through lower, e.g. to apply the initial development lowering.
+func (r *releaseReport) setCommits(commits []Commit, rules []BumpRule, lower func(bump string) string) {
+	for _, commit := range commits {
+		if commit.Merge || !commit.Conventional {
+			continue
+		}
+		bump := commitBumpType(commit, rules)
+		if commit.Breaking {
+			bump = "major"
+		}
+		if bump == "none" {
+			continue
+		}
+		r.Commits = append(r.Commits, commitReport{SHA: commit.SHA, Header: commit.Header, Type: commit.Type, Scope: commit.Scope, Breaking: commit.Breaking, Bump: lower(bump)})
+	}
+}
+
+// finish completes the report with the outcome of the run, passes it on to the CI system
+// and writes it in JSON mode.
+func (r *runReport) finish(cfg *Config, err error) {
+	r.Success = err == nil
+	if err != nil {
+		r.Error = &errorReport{Code: errorCode(err), Message: err.Error()}
+	}
+	if cfg != nil {
+		r.DryRun = cfg.DryRun
+		r.Branch = cfg.Branch
+		writeCIOutputs(cfg, r)
+	}
+	if r.out == nil {
+		return
+	}
+
+	encoder := json.NewEncoder(r.out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON output: %v\n", err)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runPromote implements the "promote" command: the latest pre-release of the target, or the
+// one named in args, is released as its final version. The final tag is created on the commit
+// of the pre-release, and the release notes cover all commits since the previous final release.
+func runPromote(cfg *Config, target releaseTarget, args []string, opts releaseOptions) error {
+	if len(args) > 1 {
+		return withCode(errCodeUsage, fmt.Errorf("promote takes at most one pre-release tag or version, got %d", len(args)))
+	}
+	if opts.SetVersion != "" {
+		return withCode(errCodeUsage, fmt.Errorf("-set-version cannot be used with promote, the version is taken from the pre-release"))
+	}
+
+	if cfg.DryRun {
+		fmt.Println("Dry run mode enabled: No actual changes will be made to the Git repository or files.")
+	}
+	if !cfg.SkipChecks {
+		if err := validateGitConfig(); err != nil {
+			return withCode(errCodeGitConfig, fmt.Errorf("git configuration error: %v", err))
+		}
+		if err := checkGitStatus(); err != nil {
+			return withCode(errCodeDirtyWorktree, fmt.Errorf("git status check failed: %v", err))
+		}
+	}
+	if cfg.CIEnv.MergeRef && cfg.Branch == "" {
+		return fmt.Errorf("cannot promote from the merge commit of pull request #%s, run promote on a branch build", cfg.CIEnv.PullRequest)
+	}
+	if cfg.Branch == "" {
+		branch, err := currentBranch(cfg)
+		if err != nil {
+			return fmt.Errorf("error getting current branch: %v", err)
+		}
+		cfg.Branch = branch
+	}
+
+	rep := report.startRelease(target)
+	if _, err := resolveGoModule(cfg, &target); err != nil {
+		return err
+	}
+
+	matcher := target.tagMatcher()
+	tags, err := listVersionTags(matcher)
+	if err != nil {
+		return fmt.Errorf("error getting version tags: %v", err)
+	}
+
+	// Without a name, only pre-releases in the history of HEAD are promoted, so the final
+	// version is never taken from an unmerged branch
+	var name string
+	candidates := tags
+	if len(args) == 1 {
+		name = args[0]
+	} else if candidates, err = reachableTags(tags, "HEAD", cfg.FirstParent); err != nil {
+		return err
+	}
+	preTag, err := findPrerelease(candidates, name)
+	if err != nil {
+		return withCode(errCodeNotFound, err)
+	}
+
+	final, err := preTag.Version.SetPrerelease("")
+	if err != nil {
+		return fmt.Errorf("error computing the final version of %s: %v", preTag.Name, err)
+	}
+	final, _ = final.SetMetadata("")
+	finalVersion := &final
+	version := target.Scheme.formatVersion(finalVersion)
+
+	out, err := exec.Command("git", "rev-list", "-n", "1", preTag.Name).Output()
+	if err != nil {
+		return fmt.Errorf("error resolving the commit of %s: %v", preTag.Name, err)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	// The previous release is the latest one in the history of the pre-release
+	history, err := reachableTags(tags, commit, cfg.FirstParent)
+	if err != nil {
+		return err
+	}
+	previous := getCurrentReleaseVersion(history)
+	rep.PreviousTag = previous.Name
+	if previous.Name != "" {
+		rep.PreviousVersion = target.Scheme.formatVersion(previous.Version)
+	}
+	if !finalVersion.GreaterThan(previous.Version) {
+		return withCode(errCodeInvalidVersion, fmt.Errorf("%s cannot be promoted, version %s is not newer than the latest release %s", preTag.Name, version, target.Scheme.formatVersion(previous.Version)))
+	}
+	if err := exec.Command("git", "merge-base", "--is-ancestor", commit, "HEAD").Run(); err != nil {
+		fmt.Printf("Warning: %s is not on the current branch %s, the changelog is committed to %s anyway.\n", preTag.Name, cfg.Branch, cfg.Branch)
+	}
+
+	fmt.Printf("Promoting %s (%s) of the %s to %s.\n", preTag.Name, shortSHA(commit), target.label(), version)
+
+	finalTagName, err := target.tagName(cfg, version, commit)
+	if err != nil {
+		return err
+	}
+	if err := validateTagName(finalTagName); err != nil {
+		return withCode(errCodeInvalidVersion, err)
+	}
+	if tagExists(finalTagName) {
+		return withCode(errCodeTagExists, fmt.Errorf("version %s already exists as tag %s", version, finalTagName))
+	}
+	rep.NextVersion, rep.Tag = version, finalTagName
+	if v, ok := matcher.parse(finalTagName); !ok || !v.Equal(finalVersion) {
+		fmt.Printf("Warning: tag %s will not be recognized as version %s by later runs, check tag_format and tag_prefix.\n", finalTagName, version)
+	}
+
+	if opts.NextVersionOnly {
+		fmt.Println(finalTagName)
+		rep.action("tag", actionSkipped, "next version only")
+		return nil
+	}
+
+	// The release notes are consolidated from all pre-releases since the previous final release
+	if cfg.Changelog.Enabled {
+		commits, err := getCommitsBetweenRefs(previous.Name, commit, target.Paths...)
+		if err != nil {
+			return fmt.Errorf("error getting commits for release notes: %v", err)
+		}
+		fmt.Printf("Generating release notes from %s to %s...\n", previous.Name, preTag.Name)
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would generate release notes to: %s\n", target.ChangelogPath)
+			rep.action("changelog", actionDryRun, target.ChangelogPath)
+		} else {
+			notes := changelogData{Tag: finalTagName, Version: version, PreviousTag: previous.Name}
+			if previous.Name != "" {
+				notes.PreviousVersion = target.Scheme.formatVersion(previous.Version)
+			}
+			changed, err := generateReleaseNotes(cfg, commits, notes, target.ChangelogPath)
+			if err != nil {
+				return fmt.Errorf("error generating release notes: %w", err)
+			}
+			if !changed {
+				fmt.Printf("Release notes in %s are up to date.\n", target.ChangelogPath)
+				rep.action("changelog", actionSkipped, target.ChangelogPath+" is up to date")
+			} else {
+				rep.action("changelog", actionPerformed, target.ChangelogPath)
+				fmt.Printf("Committing %s...\n", target.ChangelogPath)
+				if err := addAndCommitReleaseFiles([]string{target.ChangelogPath}, renderMessage(cfg.Changelog.CommitMessage, finalTagName)); err != nil {
+					return withCode(errCodeCommit, fmt.Errorf("error committing release files: %v", err))
+				}
+				rep.action("commit", actionPerformed, target.ChangelogPath)
+			}
+		}
+	} else {
+		rep.action("changelog", actionSkipped, "changelog disabled")
+	}
+
+	fmt.Printf("Creating tag: %s on %s\n", finalTagName, shortSHA(commit))
+	if cfg.DryRun {
+		fmt.Printf("[DRY-RUN] Would create tag: %s\n", finalTagName)
+		rep.action("tag", actionDryRun, finalTagName)
+	} else if err := createGitTag(finalTagName, renderMessage(cfg.TagMessage, finalTagName), commit); err != nil {
+		return withCode(errCodeTag, fmt.Errorf("error creating git tag: %v", err))
+	} else {
+		rep.action("tag", actionPerformed, finalTagName)
+	}
+
+	if cfg.CI || cfg.PushBranch {
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would push tag: %s\n", finalTagName)
+			rep.action("push_tag", actionDryRun, finalTagName)
+			if cfg.PushBranch && cfg.Changelog.Enabled {
+				fmt.Println("[DRY-RUN] Would also push the current branch.")
+				rep.action("push_branch", actionDryRun, cfg.Branch)
+			}
+			return nil
+		}
+		if err := pushTag(cfg.Remote, finalTagName); err != nil {
+			return withCode(errCodePush, fmt.Errorf("error pushing tag: %v", err))
+		}
+		fmt.Printf("Successfully promoted and pushed version: %s\n", finalTagName)
+		rep.action("push_tag", actionPerformed, finalTagName)
+		if cfg.Changelog.Enabled && cfg.CIEnv.PullRequest != "" {
+			rep.action("push_branch", actionSkipped, "pull request build")
+		} else if cfg.Changelog.Enabled {
+			if err := pushCurrentBranch(cfg.Remote, cfg.Branch); err != nil {
+				fmt.Printf("Warning: Could not push branch: %v\n", err)
+				rep.action("push_branch", actionFailed, err.Error())
+			} else {
+				rep.action("push_branch", actionPerformed, cfg.Branch)
+			}
+		}
+	} else {
+		rep.action("push_tag", actionSkipped, "not in CI mode")
+		fmt.Printf("Promoted version: %s\n", finalTagName)
+		fmt.Printf("Run 'git push %s %s' to push the tag to remote.\n", cfg.Remote, finalTagName)
+	}
+	return nil
+}
+
+// findPrerelease returns the pre-release tag with the given tag name or version, or the
+// highest pre-release if name is empty.
+func findPrerelease(tags []versionTag, name string) (versionTag, error) {
+	for _, tag := range tags { // Highest version first
+		if tag.Version.Prerelease() == "" {
+			continue
+		}
+		if name == "" || tag.Name == name || tag.Version.String() == strings.TrimPrefix(name, "v") {
+			return tag, nil
+		}
+	}
+
+	if name == "" {
+		return versionTag{}, fmt.Errorf("no pre-release found to promote in the history of HEAD, name one to promote it anyway")
+	}
+	for _, tag := range tags {
+		if tag.Name == name {
+			return versionTag{}, fmt.Errorf("%s is not a pre-release", name)
+		}
+	}
+	return versionTag{}, fmt.Errorf("pre-release %s not found", name)
+}
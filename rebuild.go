@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runChangelogCommand implements the "changelog" command.
+func runChangelogCommand(cfg *Config, target releaseTarget, args []string) error {
+	usage := withCode(errCodeUsage, fmt.Errorf("usage: semvergo [flags] changelog rebuild [-force]"))
+	if len(args) == 0 || args[0] != "rebuild" {
+		return usage
+	}
+	fs := flag.NewFlagSet("changelog rebuild", flag.ContinueOnError)
+	force := fs.Bool("force", false, "Replace the changelog file even if it is not in a recognized format")
+	if err := fs.Parse(args[1:]); err != nil {
+		return withCode(errCodeUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return usage
+	}
+	return rebuildChangelog(cfg, target, *force)
+}
+
+// rebuildChangelog writes the complete changelog of the target from its version tags. The release
+// notes of every final release in the history of HEAD cover the commits since the previous
+// release and are dated with the tag. The title and introduction of an existing file are kept,
+// as is the [Unreleased] section of a Keep a Changelog file. A file in an unknown format is only
+// replaced if force is set. The file is not committed.
+func rebuildChangelog(cfg *Config, target releaseTarget, force bool) error {
+	matcher := target.tagMatcher()
+	tags, err := listVersionTags(matcher)
+	if err != nil {
+		return fmt.Errorf("error getting version tags: %v", err)
+	}
+	tags, err = reachableTags(tags, "HEAD", cfg.FirstParent)
+	if err != nil {
+		return err
+	}
+	var releases []versionTag
+	for i := len(tags) - 1; i >= 0; i-- { // Oldest first
+		if tags[i].Version.Prerelease() == "" {
+			releases = append(releases, tags[i])
+		}
+	}
+	if len(releases) == 0 {
+		return withCode(errCodeNotFound, fmt.Errorf("no releases of the %s found in the history of HEAD, looking for %s", target.label(), matcher.describe()))
+	}
+	dates, err := tagDates()
+	if err != nil {
+		return err
+	}
+
+	keepAChangelog := cfg.Changelog.Format == "keepachangelog"
+	var doc changelogDocument
+	if existing, err := os.ReadFile(target.ChangelogPath); err == nil {
+		if previous, err := parseChangelog(string(existing)); err != nil && !force {
+			return withCode(errCodeChangelogFormat, fmt.Errorf("refusing to rebuild changelog file '%s', it is not in a recognized format: %v (use changelog rebuild -force to replace it)", target.ChangelogPath, err))
+		} else if err != nil {
+			fmt.Printf("Warning: the existing content of %s is replaced, %v\n", target.ChangelogPath, err)
+		} else {
+			doc.Preamble = previous.Preamble
+			if unreleased := previous.section("Unreleased"); unreleased >= 0 && keepAChangelog {
+				doc.Sections = append(doc.Sections, previous.Sections[unreleased])
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read existing changelog file '%s': %v", target.ChangelogPath, err)
+	}
+	if keepAChangelog {
+		if doc.Preamble == "" {
+			doc.Preamble = keepAChangelogPreamble(cfg)
+		}
+		if doc.section("Unreleased") < 0 {
+			doc.Sections = append(doc.Sections, changelogSection{Name: "Unreleased", Text: "## [Unreleased]\n"})
+		}
+	}
+
+	fmt.Printf("Rebuilding %s from %d releases of the %s...\n", target.ChangelogPath, len(releases), target.label())
+	repoURL := repositoryURL(cfg.Remote)
+	var previous versionTag
+	for _, tag := range releases {
+		commits, err := getCommitsBetweenRefs(previous.Name, tag.Name, target.Paths...)
+		if err != nil {
+			return fmt.Errorf("error getting commits for release notes: %v", err)
+		}
+		data := changelogData{Tag: tag.Name, Version: target.Scheme.formatVersion(tag.Version), PreviousTag: previous.Name, Date: dates[tag.Name]}
+		if previous.Name != "" {
+			data.PreviousVersion = target.Scheme.formatVersion(previous.Version)
+		}
+		section, err := renderReleaseNotes(cfg, commits, data)
+		if err != nil {
+			return err
+		}
+
+		if keepAChangelog {
+			doc.putSection(changelogSection{Name: data.Version, Text: section})
+			setKeepAChangelogLinks(&doc, repoURL, data)
+		} else {
+			doc.putSection(changelogSection{Name: data.Tag, Text: section})
+		}
+		previous = tag
+	}
+
+	if cfg.DryRun {
+		fmt.Printf("[DRY-RUN] Would write %s:\n\n%s", target.ChangelogPath, doc.String())
+		report.action("changelog", actionDryRun, target.ChangelogPath)
+		return nil
+	}
+	if err := os.WriteFile(target.ChangelogPath, []byte(doc.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog to file '%s': %v", target.ChangelogPath, err)
+	}
+	fmt.Printf("Rebuilt %s, review and commit it.\n", target.ChangelogPath)
+	report.action("changelog", actionPerformed, target.ChangelogPath)
+	return nil
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// releaseTarget is a versioned unit of the repository: either the repository as a whole
+// or a single package of a monorepo.
+type releaseTarget struct {
+	Name          string   // Package name, empty for the repository
+	Dir           string   // Root directory of the target relative to the repository root
+	Paths         []string // Path globs the commits must touch, empty for the whole repository
+	TagPrefix     string
+	TagFormat     string // Empty to tag as TagPrefix + version
+	ChangelogPath string
+	VersionFiles  []VersionFileConfig // Files whose version is updated on release
+	Scheme        versionScheme
+}
+
+// label returns a human readable name of the target for log messages.
+func (t releaseTarget) label() string {
+	if t.Name == "" {
+		return "repository"
+	}
+	return "package " + t.Name
+}
+
+// releaseOptions are the per invocation settings of a release that are not part of the configuration.
+type releaseOptions struct {
+	SetVersion      string
+	NextVersionOnly bool
+}
+
+// repositoryTarget returns the target versioning the repository as a whole.
+func repositoryTarget(cfg *Config) releaseTarget {
+	return releaseTarget{
+		Dir:           ".",
+		TagPrefix:     cfg.TagPrefix,
+		TagFormat:     cfg.TagFormat,
+		ChangelogPath: cfg.Changelog.Path,
+		VersionFiles:  cfg.VersionFiles,
+		Scheme:        cfg.versionScheme(),
+	}
+}
+
+// selectTarget returns the release target of the named package, or of the repository if name is empty.
+func selectTarget(cfg *Config, packageName string) (releaseTarget, error) {
+	if packageName == "" {
+		return repositoryTarget(cfg), nil
+	}
+	pkg, ok := cfg.findPackage(packageName)
+	if !ok {
+		return releaseTarget{}, fmt.Errorf("package %q is not defined in the config file.", packageName)
+	}
+	return packageTarget(cfg, pkg), nil
+}
+
+// prepareRelease runs the checks shared by all release flows and resolves the branch
+// and pre-release mode in cfg. It returns false if no release should be made from the branch.
+func prepareRelease(cfg *Config) (bool, error) {
+	if cfg.DryRun {
+		fmt.Println("Dry run mode enabled: No actual changes will be made to the Git repository or files.")
+	}
+
+	if cfg.Debug {
+		if cfg.Source != "" {
+			fmt.Printf("DEBUG: Loaded config file: %s\n", cfg.Source)
+		}
+		fmt.Printf("DEBUG: Raw tagFormat value: '%s'\n", cfg.TagFormat)
+	}
+
+	if !cfg.SkipChecks {
+		if err := validateGitConfig(); err != nil {
+			return false, withCode(errCodeGitConfig, fmt.Errorf("Git configuration error: %v", err))
+		}
+
+		if err := checkGitStatus(); err != nil {
+			return false, withCode(errCodeDirtyWorktree, fmt.Errorf("Git status check failed: %v", err))
+		}
+	} else if cfg.CI {
+		fmt.Println("Skipping Git checks in CI mode.")
+	}
+
+	if env := cfg.CIEnv; env.Provider != "" {
+		fmt.Printf("Detected CI provider: %s\n", env.Provider)
+		if env.PullRequest != "" {
+			fmt.Printf("Building pull request #%s from branch %s\n", env.PullRequest, env.Branch)
+		}
+		// The merge commit is not on any branch, a tag on it would point to a commit that is never released
+		if env.MergeRef && cfg.Branch == "" {
+			fmt.Printf("Building the merge commit of pull request #%s, no release is made from merge builds.\n", env.PullRequest)
+			return false, nil
+		}
+		// Builds of tags are usually triggered by the tags SemVerGo pushes itself
+		if env.Tag != "" && cfg.Branch == "" {
+			fmt.Printf("Building tag %s, no release is made from tag builds.\n", env.Tag)
+			return false, nil
+		}
+	}
+
+	if cfg.Branch == "" {
+		branch, err := currentBranch(cfg)
+		if err != nil {
+			return false, fmt.Errorf("Error getting current branch: %v", err)
+		}
+		cfg.Branch = branch
+	}
+
+	if maintenance, ok := cfg.maintenanceFor(cfg.Branch); ok {
+		cfg.VersionRange = maintenance.Range
+		cfg.PrereleaseID = sanitizeBranchName(cfg.Branch)
+		fmt.Printf("Maintenance branch %s: releasing versions %s, pre-release mode: %v\n", cfg.Branch, cfg.VersionRange, cfg.PreRelease)
+	} else if channel, ok := cfg.channelFor(cfg.Branch); ok {
+		if channel.Skip {
+			fmt.Printf("Branch %s matches channel '%s' which is not released.\n", cfg.Branch, channel.Branch)
+			return false, nil
+		}
+		cfg.PreRelease = true
+		cfg.PrereleaseID = channel.identifier(cfg.Branch)
+		fmt.Printf("Using pre-release channel '%s' for branch: %s\n", cfg.PrereleaseID, cfg.Branch)
+	} else if !cfg.PreRelease && !isDefaultBranch(cfg.Remote, cfg.Branch) {
+		cfg.PreRelease = true
+		cfg.PrereleaseID = sanitizeBranchName(cfg.Branch)
+		fmt.Printf("Auto-enabled pre-release for non-default branch: %s\n", cfg.Branch)
+	} else {
+		cfg.PrereleaseID = sanitizeBranchName(cfg.Branch)
+		fmt.Printf("Pre-release mode: %v\n", cfg.PreRelease)
+	}
+
+	if cfg.CI {
+		fmt.Printf("CI Mode: Branch: %s, Pre-release: %v\n", cfg.Branch, cfg.PreRelease)
+	}
+
+	// Validate the latest commit message for format, the bump type is determined from all relevant commits
+	latestCommitMsgBytes, err := exec.Command("git", "log", "-1", "--pretty=%B").Output()
+	if err != nil {
+		return false, fmt.Errorf("Error getting latest commit message for validation: %v", err)
+	}
+	latestCommitMsg := strings.TrimSpace(string(latestCommitMsgBytes))
+
+	if ok, errMsg := validateCommitMessage(latestCommitMsg); !ok {
+		return false, withCode(errCodeCommitMessage, fmt.Errorf("Invalid latest commit message: %s", errMsg))
+	}
+	fmt.Printf("Valid commit message: %s\n", latestCommitMsg)
+
+	return true, nil
+}
+
+// resolveGoModule loads the Go module of the target, if any, and excludes the commits of its
+// nested modules from the target.
+func resolveGoModule(cfg *Config, target *releaseTarget) (*goModule, error) {
+	goMod, err := loadGoModule(cfg, *target)
+	if err != nil || goMod == nil {
+		return nil, err
+	}
+	// Nested Go modules are versioned on their own, their commits don't affect this module
+	if excludes := goMod.excludePaths(); len(excludes) > 0 {
+		target.Paths = append(append([]string{}, target.Paths...), excludes...)
+	}
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Go module %s in %s, nested modules: %v\n", goMod.Path, goMod.Dir, goMod.NestedDirs)
+	}
+	return goMod, nil
+}
+
+// release computes the next version of the target from its commit history and, unless
+// only the next version is requested, writes the changelog, creates the tag and pushes it.
+// It returns the tag name of the new version, or an empty string if no release is needed.
+func release(cfg *Config, target releaseTarget, opts releaseOptions) (string, error) {
+	rep := report.startRelease(target)
+	rep.Prerelease = cfg.PreRelease
+	goMod, err := resolveGoModule(cfg, &target)
+	if err != nil {
+		return "", err
+	}
+
+	// Get current version to determine the commit range for analysis
+	matcher := target.tagMatcher()
+	allTags, err := listVersionTags(matcher)
+	if err != nil {
+		return "", fmt.Errorf("Error getting current version for commit analysis: %v", err)
+	}
+	// The current version is taken from the history of HEAD only, so tags of unmerged branches
+	// and other version lines don't affect it
+	tags, err := reachableTags(allTags, "HEAD", cfg.FirstParent)
+	if err != nil {
+		return "", fmt.Errorf("Error getting current version for commit analysis: %v", err)
+	}
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Found %d version tags of the %s (%s), %d reachable from HEAD\n", len(allTags), target.label(), matcher.describe(), len(tags))
+	}
+
+	// Releases and pre-releases are based on the last release, earlier pre-releases only
+	// determine the counter, e.g. 1.4.0 after 1.3.2 and 1.4.0-rc.0 with a feat commit
+	currentTag := getCurrentReleaseVersion(tags)
+	currentVersion := currentTag.Version
+	fromRef := currentTag.Name // Empty if there is no version tag yet, then all commits are used
+	rep.PreviousTag = fromRef
+	if fromRef != "" {
+		rep.PreviousVersion = target.Scheme.formatVersion(currentVersion)
+	}
+
+	commits, err := getCommitsBetweenRefs(fromRef, "HEAD", target.Paths...)
+	if err != nil {
+		return "", fmt.Errorf("Error getting commits for analysis: %v", err)
+	}
+
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Commits of the %s for bump type analysis (from %s to HEAD):\n", target.label(), fromRef)
+		for i, commit := range commits {
+			fmt.Printf("  - %d: %s '%s'\n", i, shortSHA(commit.SHA), commit.Header)
+		}
+	}
+
+	bumpType, err := determineBumpType(commits, cfg.BumpRules) // Pass all relevant commits
+	if err != nil {
+		return "", fmt.Errorf("Error determining version bump type: %v", err)
+	}
+
+	// While the major version is 0 the API is not stable yet, see initialDevelopmentBump.
+	// Before the first release only breaking changes are lowered, so the first release is 0.1.0.
+	lowerBump := func(bump string) string { return bump }
+	if cfg.InitialDevelopment && cfg.Scheme == "semver" && currentVersion.Major() == 0 {
+		lowerBump = func(bump string) string {
+			if currentTag.Name != "" || bump == "major" {
+				return initialDevelopmentBump(bump)
+			}
+			return bump
+		}
+	}
+	if lowered := lowerBump(bumpType); lowered != bumpType {
+		fmt.Printf("Initial development (0.x): %s bump lowered to %s, use a '%s: 1.0.0' footer to release 1.0.0.\n", bumpType, lowered, releaseAsFooter)
+		bumpType = lowered
+	}
+	rep.setCommits(commits, cfg.BumpRules, lowerBump)
+
+	// A Release-As footer explicitly sets the next version, e.g. to release 1.0.0
+	var releaseAs string
+	if value, ok := requestedVersion(commits); ok && opts.SetVersion == "" {
+		v, err := target.Scheme.parseVersion(strings.TrimPrefix(value, target.TagPrefix))
+		if err != nil {
+			return "", withCode(errCodeInvalidVersion, fmt.Errorf("Error: invalid version in %s footer: %v", releaseAsFooter, err))
+		}
+		if v.GreaterThan(currentVersion) {
+			releaseAs = target.Scheme.formatVersion(v)
+			fmt.Printf("Version %s requested by a %s footer.\n", releaseAs, releaseAsFooter)
+		} else {
+			fmt.Printf("Warning: ignoring %s: %s, the version is not newer than %s.\n", releaseAsFooter, value, target.Scheme.formatVersion(currentVersion))
+		}
+	}
+
+	rep.Bump = bumpType
+	if bumpType == "none" && releaseAs == "" {
+		fmt.Printf("No version bump needed for the %s based on commit history.\n", target.label())
+		rep.action("tag", actionSkipped, "no version bump needed")
+		return "", nil
+	}
+
+	// A new pre-release is only needed if something changed since the latest one of the channel
+	if cfg.PreRelease && opts.SetVersion == "" {
+		if latest, ok := latestPrerelease(tags, cfg.PrereleaseID); ok && latest.Version.GreaterThan(currentVersion) {
+			newCommits, err := getCommitsBetweenRefs(latest.Name, "HEAD", target.Paths...)
+			if err != nil {
+				return "", fmt.Errorf("Error getting commits since %s: %v", latest.Name, err)
+			}
+			_, requested := requestedVersion(newCommits)
+			if newBump, _ := determineBumpType(newCommits, cfg.BumpRules); newBump == "none" && !requested {
+				fmt.Printf("No version bump needed for the %s since pre-release %s.\n", target.label(), latest.Name)
+				rep.action("tag", actionSkipped, "no changes since pre-release "+latest.Name)
+				return "", nil
+			}
+		}
+	}
+
+	fmt.Printf("Based on commit history, will perform %s version bump of the %s.\n", bumpType, target.label())
+
+	var newVersion string
+	if opts.SetVersion != "" {
+		v, verErr := target.Scheme.parseVersion(strings.TrimPrefix(opts.SetVersion, target.TagPrefix))
+		if verErr != nil {
+			return "", withCode(errCodeInvalidVersion, fmt.Errorf("Error: Invalid version format: %v", verErr))
+		}
+		newVersion = target.Scheme.formatVersion(v)
+	} else if releaseAs != "" {
+		newVersion = releaseAs
+		if cfg.PreRelease {
+			v, _ := target.Scheme.parseVersion(releaseAs)
+			pre, err := nextPrerelease(*v, cfg.PrereleaseID, allTags)
+			if err != nil {
+				return "", fmt.Errorf("Error calculating new version: %v", err)
+			}
+			newVersion = target.Scheme.formatVersion(&pre)
+		}
+	} else {
+		newVersion, err = target.Scheme.nextVersion(currentVersion, bumpType, cfg.PreRelease, cfg.PrereleaseID, allTags) // Counters must be unique across branches
+		if err != nil {
+			return "", fmt.Errorf("Error calculating new version: %v", err)
+		}
+	}
+
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Calculated newVersion string: '%s'\n", newVersion)
+	}
+
+	newV, err := target.Scheme.parseVersion(newVersion)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing calculated new version '%s': %v", newVersion, err)
+	}
+	if err := cfg.checkVersionRange(newV); err != nil {
+		return "", withCode(errCodeVersionRange, err)
+	}
+
+	finalTagName, err := target.tagName(cfg, newVersion, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if err := validateTagName(finalTagName); err != nil {
+		return "", withCode(errCodeInvalidVersion, err)
+	}
+	rep.NextVersion, rep.Tag = newVersion, finalTagName
+	if v, ok := matcher.parse(finalTagName); !ok || !v.Equal(newV) {
+		fmt.Printf("Warning: tag %s will not be recognized as version %s by later runs, check tag_format and tag_prefix.\n", finalTagName, newVersion)
+	}
+
+	if opts.SetVersion != "" && tagExists(finalTagName) {
+		return "", withCode(errCodeTagExists, fmt.Errorf("Error: Version %s already exists as tag %s.", newVersion, finalTagName))
+	}
+
+	var newModulePath string
+	if goMod != nil && cfg.Scheme == "calver" {
+		fmt.Printf("Warning: the Go module %s is released with calendar versions, Go module major version checks are skipped.\n", goMod.Path)
+	} else if goMod != nil {
+		newModulePath, err = goMod.checkMajorVersion(cfg, newVersion)
+		if err != nil {
+			return "", withCode(errCodeGoModule, err)
+		}
+		goMod.checkTagName(finalTagName, newVersion)
+	}
+
+	// If only the next version is requested, the caller prints it
+	if opts.NextVersionOnly {
+		rep.action("tag", actionSkipped, "next version only")
+		return finalTagName, nil
+	}
+
+	var releaseFiles []string // Files changed for the release, committed before tagging
+
+	if newModulePath != "" {
+		fmt.Printf("Rewriting Go module path %s to %s...\n", goMod.Path, newModulePath)
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would rewrite the module path in %s and the imports of the module.\n", path.Join(goMod.Dir, "go.mod"))
+			rep.action("go_module", actionDryRun, newModulePath)
+		} else {
+			changed, err := goMod.rewriteModulePath(newModulePath)
+			if err != nil {
+				return "", fmt.Errorf("Error rewriting Go module path: %v", err)
+			}
+			releaseFiles = append(releaseFiles, changed...)
+			rep.action("go_module", actionPerformed, newModulePath)
+		}
+	}
+
+	for _, vf := range target.VersionFiles {
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would update the version in %s to %s\n", vf.Path, newVersion)
+			rep.action("version_file", actionDryRun, vf.Path)
+			continue
+		}
+		changed, err := updateVersionFile(vf, newVersion)
+		if err != nil {
+			return "", fmt.Errorf("Error updating version file %s: %v", vf.Path, err)
+		}
+		if changed {
+			fmt.Printf("Updated the version in %s to %s\n", vf.Path, newVersion)
+			releaseFiles = append(releaseFiles, vf.Path)
+			rep.action("version_file", actionPerformed, vf.Path)
+		} else {
+			rep.action("version_file", actionSkipped, vf.Path+" is up to date")
+		}
+	}
+
+	changelogPath := target.ChangelogPath
+
+	// Generate Release Notes if the changelog is enabled AND not in pre-release mode
+	// Release notes are typically generated for final releases, not pre-releases.
+	if cfg.Changelog.Enabled && !cfg.PreRelease {
+		// The commits since the previous release tag up to "HEAD" are used, as the new tag might not exist yet.
+		fmt.Printf("Generating release notes from %s to %s (HEAD)...\n", fromRef, finalTagName) // Log finalTagName for clarity
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would generate release notes to: %s\n", changelogPath)
+			rep.action("changelog", actionDryRun, changelogPath)
+		} else {
+			notes := changelogData{Tag: finalTagName, Version: newVersion, PreviousTag: fromRef}
+			if fromRef != "" {
+				notes.PreviousVersion = target.Scheme.formatVersion(currentVersion)
+			}
+			if changed, err := generateReleaseNotes(cfg, commits, notes, changelogPath); err != nil {
+				fmt.Printf("Error generating release notes: %v\n", err)
+				// Don't exit, allow tag creation to proceed even if notes fail
+				rep.action("changelog", actionFailed, err.Error())
+			} else if !changed {
+				fmt.Printf("Release notes in %s are up to date.\n", changelogPath)
+				rep.action("changelog", actionSkipped, changelogPath+" is up to date")
+			} else {
+				fmt.Printf("Release notes generated and saved to %s\n", changelogPath)
+				releaseFiles = append(releaseFiles, changelogPath)
+				rep.action("changelog", actionPerformed, changelogPath)
+			}
+		}
+	} else if cfg.Changelog.Enabled {
+		rep.action("changelog", actionSkipped, "pre-release")
+	} else {
+		rep.action("changelog", actionSkipped, "changelog disabled")
+	}
+
+	// Add and commit the changelog and other release files if any were changed. A commit of only
+	// the changelog keeps the changelog commit message.
+	if len(releaseFiles) > 0 {
+		message := cfg.CommitMessage
+		if len(releaseFiles) == 1 && releaseFiles[0] == changelogPath {
+			message = cfg.Changelog.CommitMessage
+		}
+		fmt.Printf("Committing %s...\n", strings.Join(releaseFiles, ", "))
+		if err := addAndCommitReleaseFiles(releaseFiles, renderMessage(message, finalTagName)); err != nil {
+			return "", withCode(errCodeCommit, fmt.Errorf("Error committing release files: %v", err)) // This is a critical step, so fail
+		}
+		fmt.Printf("Release files committed.\n")
+		rep.action("commit", actionPerformed, strings.Join(releaseFiles, ", "))
+	}
+
+	// Create git tag
+	fmt.Printf("Creating tag: %s\n", finalTagName)
+	if cfg.DryRun {
+		fmt.Printf("[DRY-RUN] Would create tag: %s\n", finalTagName)
+		rep.action("tag", actionDryRun, finalTagName)
+	} else {
+		if err := createGitTag(finalTagName, renderMessage(cfg.TagMessage, finalTagName), "HEAD"); err != nil {
+			return "", withCode(errCodeTag, fmt.Errorf("Error creating git tag: %v", err))
+		}
+		rep.action("tag", actionPerformed, finalTagName)
+	}
+
+	// Push tag if in CI mode or push-branch is enabled
+	if cfg.CI || cfg.PushBranch {
+		if cfg.DryRun {
+			fmt.Printf("[DRY-RUN] Would push tag: %s\n", finalTagName)
+			rep.action("push_tag", actionDryRun, finalTagName)
+			if cfg.PushBranch {
+				fmt.Println("[DRY-RUN] Would also push the current branch.")
+				rep.action("push_branch", actionDryRun, cfg.Branch)
+			}
+		} else {
+			if err := pushTag(cfg.Remote, finalTagName); err != nil {
+				return "", withCode(errCodePush, fmt.Errorf("Error pushing tag: %v", err))
+			}
+			fmt.Printf("Successfully created and pushed version: %s\n", finalTagName)
+			rep.action("push_tag", actionPerformed, finalTagName)
+
+			// Push the branch if push-branch is enabled. The branch of a pull request belongs to its author.
+			if cfg.CIEnv.PullRequest != "" {
+				rep.action("push_branch", actionSkipped, "pull request build")
+			} else if err := pushCurrentBranch(cfg.Remote, cfg.Branch); err != nil {
+				fmt.Printf("Warning: Could not push branch: %v\n", err)
+				rep.action("push_branch", actionFailed, err.Error())
+			} else {
+				fmt.Printf("Successfully pushed branch to remote.\n")
+				rep.action("push_branch", actionPerformed, cfg.Branch)
+			}
+		}
+	} else {
+		rep.action("push_tag", actionSkipped, "not in CI mode")
+		fmt.Printf("New version created: %s\n", finalTagName)
+		fmt.Printf("Run 'git push %s %s' to push the tag to remote.\n", cfg.Remote, finalTagName)
+	}
+
+	return finalTagName, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// scpRemotePattern matches scp-like remote URLs, e.g. "git@github.com:owner/repo.git".
+var scpRemotePattern = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// repositoryURL returns the web URL of the repository behind a git remote, e.g.
+// "https://github.com/owner/repo", or an empty string if it cannot be determined.
+func repositoryURL(remote string) string {
+	out, err := exec.Command("git", "remote", "get-url", remote).Output()
+	if err != nil {
+		return ""
+	}
+	return webURL(strings.TrimSpace(string(out)))
+}
+
+// webURL converts a git remote URL (https, ssh or scp-like) to the web URL of the repository.
+func webURL(remoteURL string) string {
+	var host, repoPath string
+	if u, err := url.Parse(remoteURL); err == nil && u.Scheme != "" && u.Host != "" {
+		switch u.Scheme {
+		case "http", "https", "ssh", "git":
+		default:
+			return ""
+		}
+		host, repoPath = u.Hostname(), u.Path
+		if u.Scheme == "http" && u.Port() != "" {
+			host = u.Host
+		}
+	} else if m := scpRemotePattern.FindStringSubmatch(remoteURL); m != nil {
+		host, repoPath = m[1], m[2]
+	} else {
+		return ""
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s", host, repoPath)
+}
+
+// tagURL returns the URL of a tag on the repository's web interface.
+func tagURL(repoURL, tag string) string {
+	switch {
+	case strings.Contains(repoURL, "gitlab"):
+		return fmt.Sprintf("%s/-/tags/%s", repoURL, tag)
+	case strings.Contains(repoURL, "bitbucket.org"):
+		return fmt.Sprintf("%s/src/%s", repoURL, tag)
+	default:
+		return fmt.Sprintf("%s/releases/tag/%s", repoURL, tag)
+	}
+}
+
+// commitURL returns the URL of a commit on the repository's web interface.
+func commitURL(repoURL, sha string) string {
+	switch {
+	case strings.Contains(repoURL, "gitlab"):
+		return fmt.Sprintf("%s/-/commit/%s", repoURL, sha)
+	case strings.Contains(repoURL, "bitbucket.org"):
+		return fmt.Sprintf("%s/commits/%s", repoURL, sha)
+	default:
+		return fmt.Sprintf("%s/commit/%s", repoURL, sha)
+	}
+}
+
+// defaultIssueURL returns the URL of issue {{.ID}} on the repository's issue tracker, or an
+// empty string if the repository URL is unknown. GitHub redirects to pull requests as well.
+func defaultIssueURL(repoURL string) string {
+	switch {
+	case repoURL == "":
+		return ""
+	case strings.Contains(repoURL, "gitlab"):
+		return repoURL + "/-/issues/{{.ID}}"
+	default:
+		return repoURL + "/issues/{{.ID}}"
+	}
+}
+
+// compareURL returns the URL of the diff between two tags on the repository's web interface.
+func compareURL(repoURL, fromTag, toTag string) string {
+	if repoURL == "" || fromTag == "" || toTag == "" {
+		return ""
+	}
+	switch {
+	case strings.Contains(repoURL, "gitlab"):
+		return fmt.Sprintf("%s/-/compare/%s...%s", repoURL, fromTag, toTag)
+	case strings.Contains(repoURL, "bitbucket.org"):
+		return fmt.Sprintf("%s/branches/compare/%s%%0D%s", repoURL, toTag, fromTag)
+	default: // GitHub, Gitea and Forgejo
+		return fmt.Sprintf("%s/compare/%s...%s", repoURL, fromTag, toTag)
+	}
+}
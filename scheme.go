@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// versionScheme defines how the versions of a release target are written and computed.
+// Versions are represented as semver.Version internally, which orders them for all schemes.
+type versionScheme interface {
+	// parseVersion parses the version part of a tag name.
+	parseVersion(version string) (*semver.Version, error)
+	// formatVersion writes a version as it appears in tag names.
+	formatVersion(v *semver.Version) string
+	// numberPattern is the regular expression matching a single numeric part of a version.
+	numberPattern() string
+	// nextVersion computes the version following current, see calculateNewVersion.
+	nextVersion(current *semver.Version, bumpType string, preRelease bool, identifier string, tags []versionTag) (string, error)
+}
+
+// versionScheme returns the configured versioning scheme.
+func (c *Config) versionScheme() versionScheme {
+	if c.Scheme == "calver" {
+		scheme, err := newCalVerScheme(c.CalVer.Format)
+		if err == nil { // Validated when the configuration is loaded
+			return scheme
+		}
+	}
+	return semverScheme{}
+}
+
+// semverScheme is the default Semantic Versioning 2.0.0 scheme, see https://semver.org.
+type semverScheme struct{}
+
+func (semverScheme) parseVersion(version string) (*semver.Version, error) {
+	return semver.NewVersion(version)
+}
+
+func (semverScheme) formatVersion(v *semver.Version) string {
+	return v.String()
+}
+
+func (semverScheme) numberPattern() string {
+	return `0|[1-9][0-9]*`
+}
+
+func (semverScheme) nextVersion(current *semver.Version, bumpType string, preRelease bool, identifier string, tags []versionTag) (string, error) {
+	return calculateNewVersion(current, bumpType, preRelease, identifier, tags)
+}
+
+// validateScheme checks the versioning scheme settings.
+func (c *Config) validateScheme() []configError {
+	switch c.Scheme {
+	case "semver":
+	case "calver":
+		if _, err := newCalVerScheme(c.CalVer.Format); err != nil {
+			return []configError{{Key: "calver.format", Message: err.Error()}}
+		}
+	default:
+		return []configError{{Key: "scheme", Message: fmt.Sprintf("invalid scheme %q, must be one of semver, calver", c.Scheme)}}
+	}
+	return nil
+}
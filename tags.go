@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// versionTag is a git tag naming a version of a release target.
+type versionTag struct {
+	Name    string
+	Version *semver.Version
+}
+
+// tagMatcher recognizes the tags of a release target and extracts their versions.
+// Tags are matched by reverse-parsing the tag format, or by the tag prefix if the
+// format is empty or cannot be reverse-parsed.
+type tagMatcher struct {
+	pattern *regexp.Regexp // Set if the tag format could be reverse-parsed
+	prefix  string
+	scheme  versionScheme
+}
+
+// tagFormatAction matches a template action of a tag format, e.g. "{{.Major}}".
+var tagFormatAction = regexp.MustCompile(`{{-?\s*(.*?)\s*-?}}`)
+
+// tagFormatGroups returns the regular expressions replacing the placeholders of a tag format,
+// given the pattern of a numeric version part. Fields that are not part of the version only
+// have to be present in the tag.
+func tagFormatGroups(number string) map[string]string {
+	return map[string]string{
+		".Major":      `(?P<major>` + number + `)`,
+		".Minor":      `(?P<minor>` + number + `)`,
+		".Patch":      `(?P<patch>` + number + `)`,
+		".Prerelease": `(?:-(?P<prerelease>[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?`,
+		".Version":    `(?P<version>(?:` + number + `)\.(?:` + number + `)\.(?:` + number + `)(?:-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)`,
+		".Metadata":   `(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?`,
+		".ShortSHA":   `(?:[0-9a-f]+)`,
+	}
+}
+
+// tagFormatVersionField matches actions using a version field, which must be plain to be reversible.
+var tagFormatVersionField = regexp.MustCompile(`\.(Major|Minor|Patch|Prerelease|Version)\b`)
+
+// tagTemplateFuncs are the functions available in tag formats.
+var tagTemplateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// tagData are the fields available in tag formats.
+type tagData struct {
+	Major       uint64
+	Minor       uint64
+	Patch       uint64
+	Prerelease  string // Includes the leading hyphen if present, e.g. "-beta.1"
+	Metadata    string // Includes the leading plus if present, e.g. "+build.5"
+	Version     string // Full version without prefix, e.g. "1.2.3-beta.1"
+	Branch      string
+	ShortSHA    string // Commit the version was computed from
+	Date        tagDate
+	PackageName string // Empty for the repository
+}
+
+// tagDate prints as 2006-01-02 and can be formatted with {{.Date.Format "20060102"}}.
+type tagDate struct {
+	time.Time
+}
+
+func (d tagDate) String() string {
+	return d.Format("2006-01-02")
+}
+
+// parseTagFormat parses a tag format as a text/template.
+func parseTagFormat(tagFormat string) (*template.Template, error) {
+	return template.New("tag").Funcs(tagTemplateFuncs).Option("missingkey=error").Parse(tagFormat)
+}
+
+// newTagMatcher returns the matcher of the tags created with the given format and prefix.
+// {{.PackageName}} only matches packageName, so the tags of other packages are not recognized.
+func newTagMatcher(tagFormat, tagPrefix, packageName string, scheme versionScheme) *tagMatcher {
+	m := &tagMatcher{prefix: tagPrefix, scheme: scheme}
+	if tagFormat == "" {
+		return m
+	}
+	groups := tagFormatGroups(scheme.numberPattern())
+	groups[".PackageName"] = regexp.QuoteMeta(packageName)
+
+	var sb strings.Builder
+	seen := map[string]bool{}
+	last := 0
+	for _, loc := range tagFormatAction.FindAllStringSubmatchIndex(tagFormat, -1) {
+		field := tagFormat[loc[2]:loc[3]]
+		group, ok := groups[field]
+		if !ok {
+			if tagFormatVersionField.MatchString(field) {
+				return m // Version fields in pipelines are not reversible, fall back to the prefix
+			}
+			group = `(?:.*?)` // Other fields only have to be present in the tag
+		}
+		if seen[field] {
+			return m
+		}
+		seen[field] = true
+		sb.WriteString(regexp.QuoteMeta(tagFormat[last:loc[0]]))
+		sb.WriteString(group)
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(tagFormat[last:]))
+
+	// A version can only be recovered if all of its core parts are in the tag
+	if !seen[".Version"] && (!seen[".Major"] || !seen[".Minor"] || !seen[".Patch"]) {
+		return m
+	}
+
+	m.pattern = regexp.MustCompile("^" + sb.String() + "$")
+	return m
+}
+
+// parse returns the version named by a tag, or false if the tag doesn't belong to the target.
+func (m *tagMatcher) parse(tag string) (*semver.Version, bool) {
+	if m.pattern == nil {
+		if !strings.HasPrefix(tag, m.prefix) {
+			return nil, false
+		}
+		v, err := m.scheme.parseVersion(strings.TrimPrefix(tag, m.prefix))
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+
+	matches := m.pattern.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, false
+	}
+	if i := m.pattern.SubexpIndex("version"); i > 0 {
+		v, err := m.scheme.parseVersion(matches[i])
+		return v, err == nil
+	}
+	version := fmt.Sprintf("%s.%s.%s",
+		matches[m.pattern.SubexpIndex("major")],
+		matches[m.pattern.SubexpIndex("minor")],
+		matches[m.pattern.SubexpIndex("patch")])
+	if i := m.pattern.SubexpIndex("prerelease"); i > 0 && matches[i] != "" {
+		version += "-" + matches[i]
+	}
+	v, err := m.scheme.parseVersion(version)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// describe returns a human readable description of the tags the matcher recognizes.
+func (m *tagMatcher) describe() string {
+	if m.pattern == nil {
+		return fmt.Sprintf("tags with prefix '%s'", m.prefix)
+	}
+	return fmt.Sprintf("tags matching '%s'", m.pattern.String())
+}
+
+// listVersionTags returns the version tags recognized by the matcher, highest version first.
+func listVersionTags(m *tagMatcher) ([]versionTag, error) {
+	out, err := exec.Command("git", "tag", "-l").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting git tags: %v", err)
+	}
+
+	var tags []versionTag
+	for _, name := range strings.Fields(string(out)) {
+		if v, ok := m.parse(name); ok {
+			tags = append(tags, versionTag{Name: name, Version: v})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Version.GreaterThan(tags[j].Version)
+	})
+	return tags, nil
+}
+
+// reachableTags returns the tags pointing to commits in the history of ref, keeping their order.
+// With firstParent only the first parent of merge commits is followed, so tags of merged
+// branches are ignored.
+func reachableTags(tags []versionTag, ref string, firstParent bool) ([]versionTag, error) {
+	if firstParent {
+		return firstParentTags(tags, ref)
+	}
+	out, err := exec.Command("git", "tag", "--merged", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing the tags in the history of %s: %v %s", ref, err, gitErrorOutput(err))
+	}
+	merged := map[string]bool{}
+	for _, name := range strings.Fields(string(out)) {
+		merged[name] = true
+	}
+
+	var reachable []versionTag
+	for _, tag := range tags {
+		if merged[tag.Name] {
+			reachable = append(reachable, tag)
+		}
+	}
+	return reachable, nil
+}
+
+// firstParentTags returns the tags pointing to commits on the first-parent history of ref.
+// git has no option to list them directly, so the history is compared with the tag commits.
+func firstParentTags(tags []versionTag, ref string) ([]versionTag, error) {
+	out, err := exec.Command("git", "rev-list", "--first-parent", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing the history of %s: %v %s", ref, err, gitErrorOutput(err))
+	}
+	history := map[string]bool{}
+	for _, sha := range strings.Fields(string(out)) {
+		history[sha] = true
+	}
+
+	// Annotated tags point to a tag object, the commit is the peeled object
+	out, err = exec.Command("git", "for-each-ref", "--format=%(refname:strip=2) %(objectname) %(*objectname)", "refs/tags").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving tag commits: %v", err)
+	}
+	tagCommits := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		tagCommits[fields[0]] = fields[len(fields)-1]
+	}
+
+	var reachable []versionTag
+	for _, tag := range tags {
+		if history[tagCommits[tag.Name]] {
+			reachable = append(reachable, tag)
+		}
+	}
+	return reachable, nil
+}
+
+// tagDates returns the dates of all tags, formatted as 2006-01-02. The date of an annotated tag
+// is the date it was created, the date of a lightweight tag is the date of its commit.
+func tagDates() (map[string]string, error) {
+	out, err := exec.Command("git", "for-each-ref", "--format=%(refname:strip=2) %(creatordate:short)", "refs/tags").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting tag dates: %v", err)
+	}
+	dates := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			dates[fields[0]] = fields[1]
+		}
+	}
+	return dates, nil
+}
+
+// tagMatcher returns the matcher of the tags of the target.
+func (t releaseTarget) tagMatcher() *tagMatcher {
+	return newTagMatcher(t.TagFormat, t.TagPrefix, t.Name, t.Scheme)
+}
+
+// tagName returns the name of the tag for a version of the target, created on the given commit.
+func (t releaseTarget) tagName(cfg *Config, version, commit string) (string, error) {
+	if t.TagFormat == "" {
+		return t.TagPrefix + version, nil
+	}
+
+	v, err := t.Scheme.parseVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing calculated new version '%s' for formatting: %v", version, err)
+	}
+
+	data := tagData{
+		Major:       v.Major(),
+		Minor:       v.Minor(),
+		Patch:       v.Patch(),
+		Version:     t.Scheme.formatVersion(v),
+		Branch:      cfg.Branch,
+		Date:        tagDate{time.Now()},
+		PackageName: t.Name,
+	}
+	if v.Prerelease() != "" {
+		data.Prerelease = "-" + v.Prerelease()
+	}
+	if v.Metadata() != "" {
+		data.Metadata = "+" + v.Metadata()
+	}
+	if out, err := exec.Command("git", "rev-parse", "--short", commit+"^{commit}").Output(); err == nil {
+		data.ShortSHA = strings.TrimSpace(string(out))
+	}
+
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Tag format data: %+v\n", data)
+	}
+
+	tmpl, err := parseTagFormat(t.TagFormat)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing tag format '%s': %v", t.TagFormat, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("Error rendering tag format '%s': %v", t.TagFormat, err)
+	}
+
+	if cfg.Debug {
+		fmt.Printf("DEBUG: Rendered tag name: '%s'\n", sb.String())
+	}
+	return sb.String(), nil
+}
+
+// validateTagName checks that a tag name is a legal git ref name, see git-check-ref-format(1).
+func validateTagName(tagName string) error {
+	if strings.TrimSpace(tagName) == "" {
+		return fmt.Errorf("Error: the tag format rendered an empty tag name")
+	}
+	if err := exec.Command("git", "check-ref-format", "refs/tags/"+tagName).Run(); err != nil {
+		return fmt.Errorf("Error: '%s' is not a valid git tag name, check the tag format", tagName)
+	}
+	return nil
+}
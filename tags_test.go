@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestTagMatcherParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		prefix      string
+		packageName string
+		tag         string
+		want        string // Empty if the tag must not match
+	}{
+		{name: "prefix", prefix: "v", tag: "v1.2.3", want: "1.2.3"},
+		{name: "prefix pre-release", prefix: "v", tag: "v1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{name: "prefix missing", prefix: "v", tag: "1.2.3"},
+		{name: "prefix not a version", prefix: "v", tag: "vnext"},
+		{name: "prefix short version", prefix: "v", tag: "v1.2", want: "1.2.0"}, // Tags are parsed leniently, as before tag formats
+		{name: "prefix of another package", prefix: "v", tag: "api/v1.2.3"},
+		{name: "empty prefix", prefix: "", tag: "1.2.3", want: "1.2.3"},
+		{name: "package prefix", prefix: "api/v", tag: "api/v1.2.0", want: "1.2.0"},
+		{name: "package prefix, repository tag", prefix: "api/v", tag: "v1.2.0"},
+		{name: "package prefix, other package", prefix: "api/v", tag: "web/v1.2.0"},
+		{name: "version field", format: "v{{.Version}}", tag: "v1.2.3", want: "1.2.3"},
+		{name: "version field pre-release", format: "v{{.Version}}", tag: "v1.2.3-beta.1", want: "1.2.3-beta.1"},
+		{name: "version field incomplete", format: "v{{.Version}}", tag: "v1.2"},
+		{name: "separate fields", format: "release-{{.Major}}.{{.Minor}}.{{.Patch}}", tag: "release-1.2.3", want: "1.2.3"},
+		{name: "separate fields, other prefix", format: "release-{{.Major}}.{{.Minor}}.{{.Patch}}", tag: "v1.2.3"},
+		{name: "separate fields without pre-release field", format: "release-{{.Major}}.{{.Minor}}.{{.Patch}}", tag: "release-1.2.3-rc.0"},
+		{name: "separate fields pre-release", format: "v{{.Major}}.{{.Minor}}.{{.Patch}}{{.Prerelease}}", tag: "v1.2.3-rc.0", want: "1.2.3-rc.0"},
+		{name: "separate fields final", format: "v{{.Major}}.{{.Minor}}.{{.Patch}}{{.Prerelease}}", tag: "v1.2.3", want: "1.2.3"},
+		{name: "fields in another order", format: "{{.Patch}}-{{.Minor}}-{{.Major}}", tag: "3-2-1", want: "1.2.3"},
+		{name: "whitespace in actions", format: "v{{ .Major }}.{{ .Minor }}.{{ .Patch }}", tag: "v1.2.3", want: "1.2.3"},
+		{name: "metadata", format: "v{{.Version}}{{.Metadata}}", tag: "v1.2.3+build.5", want: "1.2.3"},
+		{name: "other field", format: "v{{.Version}}-{{.Branch}}", tag: "v1.2.3-main", want: "1.2.3"},
+		{name: "package name", format: "{{.PackageName}}@{{.Version}}", packageName: "api", tag: "api@1.2.3", want: "1.2.3"},
+		{name: "package name, other package", format: "{{.PackageName}}@{{.Version}}", packageName: "api", tag: "web@1.2.3"},
+		{name: "package name, longer name", format: "{{.PackageName}}@{{.Version}}", packageName: "api", tag: "api-v2@1.2.3"},
+		{name: "package directory", format: "services/api/v{{.Version}}", packageName: "api", tag: "services/web/v1.2.3"},
+		{name: "pipeline falls back to the prefix", format: "v{{.Version | lower}}", prefix: "v", tag: "v1.2.3", want: "1.2.3"},
+		{name: "incomplete format falls back to the prefix", format: "v{{.Major}}.{{.Minor}}", prefix: "v", tag: "v1.2.3", want: "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTagMatcher(tt.format, tt.prefix, tt.packageName, semverScheme{})
+			v, ok := m.parse(tt.tag)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("parse(%q) = %s, want no match (%s)", tt.tag, v, m.describe())
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("parse(%q) did not match (%s), want %s", tt.tag, m.describe(), tt.want)
+			}
+			if v.String() != tt.want {
+				t.Errorf("parse(%q) = %s, want %s", tt.tag, v, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VersionFileConfig is a project file whose version is updated on every release and committed
+// together with the changelog, e.g. package.json.
+type VersionFileConfig struct {
+	Path    string `yaml:"path" toml:"path"`                           // Relative to the repository root
+	Type    string `yaml:"type,omitempty" toml:"type,omitempty"`       // See versionFileUpdaters, inferred from the file name if empty
+	Pattern string `yaml:"pattern,omitempty" toml:"pattern,omitempty"` // regex: the first group (or the group named "version") is the version
+	Name    string `yaml:"name,omitempty" toml:"name,omitempty"`       // go: name of the constant or variable, default Version
+}
+
+// textSpan is the location of a version in the content of a file.
+type textSpan struct {
+	start, end int
+}
+
+// versionFileUpdaters locate the versions to replace in a file of each type.
+var versionFileUpdaters = map[string]func(data []byte, vf VersionFileConfig) ([]textSpan, error){
+	"json":  jsonVersionSpans,
+	"chart": chartVersionSpans,
+	"pom":   pomVersionSpans,
+	"cargo": func(data []byte, _ VersionFileConfig) ([]textSpan, error) { return tomlVersionSpans(data, "package") },
+	"pyproject": func(data []byte, _ VersionFileConfig) ([]textSpan, error) {
+		return tomlVersionSpans(data, "project", "tool.poetry")
+	},
+	"plain": plainVersionSpans,
+	"go":    goVersionSpans,
+	"regex": regexVersionSpans,
+}
+
+// versionFileTypes maps well known file names to their type.
+var versionFileTypes = map[string]string{
+	"package.json":   "json",
+	"Chart.yaml":     "chart",
+	"pom.xml":        "pom",
+	"Cargo.toml":     "cargo",
+	"pyproject.toml": "pyproject",
+	"VERSION":        "plain",
+}
+
+// fileType returns the type of the version file, inferred from its name if not configured.
+func (vf VersionFileConfig) fileType() string {
+	if vf.Type != "" {
+		return vf.Type
+	}
+	if vf.Pattern != "" {
+		return "regex"
+	}
+	if t, ok := versionFileTypes[path.Base(vf.Path)]; ok {
+		return t
+	}
+	if strings.HasSuffix(vf.Path, ".go") {
+		return "go"
+	}
+	return ""
+}
+
+// updateVersionFile writes the version into the file. It reports whether the file was changed.
+func updateVersionFile(vf VersionFileConfig, version string) (bool, error) {
+	data, err := os.ReadFile(vf.Path)
+	if err != nil {
+		return false, err
+	}
+	spans, err := versionFileUpdaters[vf.fileType()](data, vf)
+	if err != nil {
+		return false, err
+	}
+	if len(spans) == 0 {
+		return false, fmt.Errorf("no version found in %s", vf.Path)
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	updated := append([]byte{}, data...)
+	for _, s := range spans {
+		newVersion := version
+		if bytes.HasPrefix(data[s.start:s.end], []byte("v")) && !strings.HasPrefix(version, "v") {
+			newVersion = "v" + version // Keep the style of the file
+		}
+		updated = append(updated[:s.start:s.start], append([]byte(newVersion), updated[s.end:]...)...)
+	}
+	if bytes.Equal(updated, data) {
+		return false, nil
+	}
+
+	info, err := os.Stat(vf.Path)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(vf.Path, updated, info.Mode())
+}
+
+// jsonVersionSpans locates the top-level "version" of a JSON document such as package.json.
+func jsonVersionSpans(data []byte, _ VersionFileConfig) ([]textSpan, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []bool // true for objects
+	expectKey := false
+	key := "" // Current key of the top-level object
+	for {
+		start := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				stack = append(stack, delim == '{')
+				expectKey = delim == '{'
+				continue
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		} else if expectKey {
+			if len(stack) == 1 {
+				key = tok.(string)
+			}
+			expectKey = false
+			continue
+		} else if len(stack) == 1 && key == "version" {
+			if _, ok := tok.(string); !ok {
+				return nil, fmt.Errorf("the version is not a string")
+			}
+			end := int(dec.InputOffset())
+			quote := bytes.IndexByte(data[start:end], '"')
+			return []textSpan{{start + quote + 1, end - 1}}, nil
+		}
+		expectKey = len(stack) > 0 && stack[len(stack)-1]
+	}
+}
+
+// chartVersionSpans locates the version and, if present, the appVersion of a Helm Chart.yaml.
+func chartVersionSpans(data []byte, _ VersionFileConfig) ([]textSpan, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping")
+	}
+
+	lineStarts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	var spans []textSpan
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "version" && key.Value != "appVersion" {
+			continue
+		}
+		if value.Kind != yaml.ScalarNode || value.Line > len(lineStarts) {
+			return nil, fmt.Errorf("%s is not a scalar", key.Value)
+		}
+		start := lineStarts[value.Line-1] + value.Column - 1
+		if value.Style == yaml.DoubleQuotedStyle || value.Style == yaml.SingleQuotedStyle {
+			start++
+		}
+		if !bytes.HasPrefix(data[start:], []byte(value.Value)) {
+			return nil, fmt.Errorf("cannot locate %s on line %d", key.Value, value.Line)
+		}
+		spans = append(spans, textSpan{start, start + len(value.Value)})
+	}
+	return spans, nil
+}
+
+// pomVersionSpans locates the version of the project in a Maven pom.xml, not the parent version.
+func pomVersionSpans(data []byte, _ VersionFileConfig) ([]textSpan, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var elements []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			elements = append(elements, t.Name.Local)
+			if len(elements) == 2 && elements[0] == "project" && elements[1] == "version" {
+				start := int(dec.InputOffset())
+				if _, err := dec.Token(); err != nil {
+					return nil, fmt.Errorf("invalid XML: %v", err)
+				}
+				end := int(dec.InputOffset())
+				text := string(data[start:end])
+				trimmed := strings.TrimSpace(text)
+				if trimmed == "" || strings.Contains(trimmed, "<") {
+					return nil, fmt.Errorf("the project version is empty")
+				}
+				start += strings.Index(text, trimmed)
+				return []textSpan{{start, start + len(trimmed)}}, nil
+			}
+		case xml.EndElement:
+			elements = elements[:len(elements)-1]
+		}
+	}
+}
+
+// tomlVersionPattern matches a version key with a string value in a TOML table.
+var tomlVersionPattern = regexp.MustCompile(`^\s*version\s*=\s*["']([^"']*)["']`)
+
+// tomlVersionSpans locates the version key in the first of the given tables that has one,
+// e.g. [package] in Cargo.toml.
+func tomlVersionSpans(data []byte, tables ...string) ([]textSpan, error) {
+	found := map[string]textSpan{}
+	table := ""
+	offset := 0
+	for _, text := range strings.Split(string(data), "\n") {
+		if tomlArrayPattern.MatchString(text) {
+			table = "" // Arrays of tables are never searched
+		} else if matches := tomlTablePattern.FindStringSubmatch(text); matches != nil {
+			table = strings.TrimSpace(matches[1])
+		} else if loc := tomlVersionPattern.FindStringSubmatchIndex(text); loc != nil {
+			if _, ok := found[table]; !ok {
+				found[table] = textSpan{offset + loc[2], offset + loc[3]}
+			}
+		}
+		offset += len(text) + 1
+	}
+	for _, t := range tables {
+		if span, ok := found[t]; ok {
+			return []textSpan{span}, nil
+		}
+	}
+	return nil, nil
+}
+
+// plainVersionSpans locates the version in a file containing nothing but the version.
+func plainVersionSpans(data []byte, _ VersionFileConfig) ([]textSpan, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	start := bytes.Index(data, trimmed)
+	return []textSpan{{start, start + len(trimmed)}}, nil
+}
+
+// goVersionSpans locates the string value of a Go constant or variable, e.g. const Version = "1.2.3".
+func goVersionSpans(data []byte, vf VersionFileConfig) ([]textSpan, error) {
+	name := vf.Name
+	if name == "" {
+		name = "Version"
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, vf.Path, data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", vf.Path, err)
+	}
+
+	var spans []textSpan
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, ident := range valueSpec.Names {
+				if ident.Name != name || i >= len(valueSpec.Values) {
+					continue
+				}
+				lit, ok := valueSpec.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return nil, fmt.Errorf("%s is not a string literal", name)
+				}
+				start := fset.Position(lit.Pos()).Offset + 1 // Inside the quotes
+				end := fset.Position(lit.End()).Offset - 1
+				spans = append(spans, textSpan{start, end})
+			}
+		}
+	}
+	return spans, nil
+}
+
+// regexVersionSpans locates all matches of the pattern of the version file.
+func regexVersionSpans(data []byte, vf VersionFileConfig) ([]textSpan, error) {
+	re, err := regexp.Compile(vf.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	group := 1
+	if i := re.SubexpIndex("version"); i > 0 {
+		group = i
+	}
+
+	var spans []textSpan
+	for _, loc := range re.FindAllSubmatchIndex(data, -1) {
+		if loc[2*group] >= 0 {
+			spans = append(spans, textSpan{loc[2*group], loc[2*group+1]})
+		}
+	}
+	return spans, nil
+}
+
+// validateVersionFiles checks version file definitions, key is the configuration key of the list.
+func validateVersionFiles(files []VersionFileConfig, key string) []configError {
+	var errs []configError
+	for i, vf := range files {
+		fileKey := fmt.Sprintf("%s.%d", key, i)
+		if strings.TrimSpace(vf.Path) == "" || strings.HasPrefix(vf.Path, "/") {
+			errs = append(errs, configError{Key: fileKey + ".path", Message: "must be a path relative to the repository root"})
+		}
+		fileType := vf.fileType()
+		if _, ok := versionFileUpdaters[fileType]; !ok {
+			errs = append(errs, configError{Key: fileKey + ".type", Message: fmt.Sprintf("unknown version file type %q for %s, must be one of json, chart, pom, cargo, pyproject, plain, go, regex", fileType, vf.Path)})
+		}
+		if fileType == "regex" {
+			if re, err := regexp.Compile(vf.Pattern); err != nil {
+				errs = append(errs, configError{Key: fileKey + ".pattern", Message: fmt.Sprintf("invalid regular expression: %v", err)})
+			} else if re.NumSubexp() == 0 {
+				errs = append(errs, configError{Key: fileKey + ".pattern", Message: "the pattern needs a group capturing the version"})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// spanTexts returns the text of each span, in order.
+func spanTexts(data string, spans []textSpan) []string {
+	var texts []string
+	for _, s := range spans {
+		texts = append(texts, data[s.start:s.end])
+	}
+	return texts
+}
+
+func TestVersionSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		vf       VersionFileConfig
+		data     string
+		want     []string // Text of the located versions, nil if none is found
+	}{
+		{
+			name:     "json top-level version",
+			fileType: "json",
+			data:     `{"name": "app", "version": "1.2.3"}`,
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "json nested version before the top-level one",
+			fileType: "json",
+			data:     "{\n  \"engines\": {\"version\": \"9.9.9\"},\n  \"versions\": [{\"version\": \"8.8.8\"}],\n  \"version\": \"1.2.3\"\n}",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "json only nested versions",
+			fileType: "json",
+			data:     `{"dependencies": {"lib": {"version": "9.9.9"}}}`,
+		},
+		{
+			name:     "json version as a value",
+			fileType: "json",
+			data:     `{"keywords": ["version"], "description": "version", "version": "v1.2.3"}`,
+			want:     []string{"v1.2.3"},
+		},
+		{
+			name:     "chart version and appVersion",
+			fileType: "chart",
+			data:     "apiVersion: v2\nname: app\nversion: 1.2.3\nappVersion: \"1.2.3\"\ndependencies:\n  - name: db\n    version: 9.9.9\n",
+			want:     []string{"1.2.3", "1.2.3"},
+		},
+		{
+			name:     "pom project version after the parent version",
+			fileType: "pom",
+			data:     "<project>\n  <parent>\n    <version>9.9.9</version>\n  </parent>\n  <artifactId>app</artifactId>\n  <version> 1.2.3 </version>\n</project>\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "pom dependency versions",
+			fileType: "pom",
+			data:     "<project>\n  <dependencies>\n    <dependency>\n      <version>9.9.9</version>\n    </dependency>\n  </dependencies>\n</project>\n",
+		},
+		{
+			name:     "cargo package table",
+			fileType: "cargo",
+			data:     "[dependencies]\nserde = { version = \"1.0\" }\n\n[package]\nname = \"app\"\nversion = \"1.2.3\"\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "cargo array of tables",
+			fileType: "cargo",
+			data:     "[[bin]]\nversion = \"9.9.9\"\n\n[package]\nversion = '1.2.3'\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "pyproject falls back to poetry",
+			fileType: "pyproject",
+			data:     "[build-system]\nversion = \"9.9.9\"\n\n[tool.poetry]\nversion = \"1.2.3\"\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "plain",
+			fileType: "plain",
+			data:     "\n1.2.3\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "go const",
+			fileType: "go",
+			vf:       VersionFileConfig{Path: "version.go"},
+			data:     "package main\n\nconst Version = \"1.2.3\"\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "go const block",
+			fileType: "go",
+			vf:       VersionFileConfig{Path: "version.go"},
+			data:     "package main\n\nconst (\n\tName    = \"app\"\n\tVersion = \"v1.2.3\"\n)\n",
+			want:     []string{"v1.2.3"},
+		},
+		{
+			name:     "go var with a custom name",
+			fileType: "go",
+			vf:       VersionFileConfig{Path: "version.go", Name: "appVersion"},
+			data:     "package main\n\nconst Version = \"9.9.9\"\n\nvar (\n\tname, appVersion = \"app\", `1.2.3`\n)\n",
+			want:     []string{"1.2.3"},
+		},
+		{
+			name:     "go name in a function",
+			fileType: "go",
+			vf:       VersionFileConfig{Path: "version.go"},
+			data:     "package main\n\nfunc main() {\n\tconst Version = \"9.9.9\"\n}\n",
+		},
+		{
+			name:     "regex first group",
+			fileType: "regex",
+			vf:       VersionFileConfig{Pattern: `image: app:(\S+)`},
+			data:     "image: app:1.2.3\nimage: db:9.9.9\nimage: app:1.2.3\n",
+			want:     []string{"1.2.3", "1.2.3"},
+		},
+		{
+			name:     "regex named group",
+			fileType: "regex",
+			vf:       VersionFileConfig{Pattern: `(image|tag): (?P<version>[0-9.]+)`},
+			data:     "tag: 1.2.3\n",
+			want:     []string{"1.2.3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, err := versionFileUpdaters[tt.fileType]([]byte(tt.data), tt.vf)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := spanTexts(tt.data, spans); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("versions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionSpansErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		vf       VersionFileConfig
+		data     string
+	}{
+		{name: "json invalid", fileType: "json", data: `{"name": app}`},
+		{name: "json version not a string", fileType: "json", data: `{"version": 1}`},
+		{name: "chart not a mapping", fileType: "chart", data: "- 1.2.3\n"},
+		{name: "chart version not a scalar", fileType: "chart", data: "version:\n  - 1.2.3\n"},
+		{name: "pom empty version", fileType: "pom", data: "<project><version></version></project>"},
+		{name: "go invalid", fileType: "go", vf: VersionFileConfig{Path: "version.go"}, data: "package main\n\nconst Version =\n"},
+		{name: "go not a string", fileType: "go", vf: VersionFileConfig{Path: "version.go"}, data: "package main\n\nconst Version = 3\n"},
+		{name: "regex invalid", fileType: "regex", vf: VersionFileConfig{Pattern: `(`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := versionFileUpdaters[tt.fileType]([]byte(tt.data), tt.vf); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}